@@ -6,6 +6,7 @@ require (
 	github.com/go-kit/log v0.2.1
 	github.com/golangci/golangci-lint/v2 v2.6.2
 	github.com/hashicorp/go-retryablehttp v0.7.8
+	github.com/klauspost/compress v1.17.2
 	github.com/maxbrunsfeld/counterfeiter/v6 v6.8.1
 	github.com/rs/xid v1.6.0
 	github.com/segmentio/encoding v0.5.3
@@ -0,0 +1,296 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+// ErrTokenRejected is wrapped by the errors returned when a token endpoint does not issue a token
+var ErrTokenRejected = errors.New("token request rejected")
+
+// WithBearerToken sets the Authorization header to a bearer token
+func WithBearerToken(token string) ClientOpt {
+	return func(req *Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// WithBasicAuth sets the Authorization header to use HTTP basic authentication
+func WithBasicAuth(username, password string) ClientOpt {
+	return func(req *Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// WithTokenSource sets the Authorization header to a token from ts. The token is
+// obtained once, when the option is applied, and reused for any retries.
+func WithTokenSource(ts TokenSource) ClientOpt {
+	return func(req *Request) error {
+		tok, err := ts.Token(req.Context())
+		if err != nil {
+			return errors.Wrap(err, "could not get token")
+		}
+
+		req.Header.Set("Authorization", tok.AuthorizationHeader())
+
+		return nil
+	}
+}
+
+// Token is an access token
+type Token struct {
+	AccessToken string
+	// TokenType is the scheme of the Authorization header (default "Bearer")
+	TokenType string
+	// Expiry is when the token expires; the zero value never expires
+	Expiry time.Time
+}
+
+// AuthorizationHeader is the value of the Authorization header for the token
+func (t *Token) AuthorizationHeader() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	return tokenType + " " + t.AccessToken
+}
+
+// TokenSource provides access tokens; it must be safe for concurrent use
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// StaticTokenSource always returns the same token
+type StaticTokenSource Token
+
+func (s StaticTokenSource) Token(context.Context) (*Token, error) {
+	tok := Token(s)
+	return &tok, nil
+}
+
+// ClientCredentials configures an OAuth2 client credentials grant (RFC 6749 section 4.4)
+//
+// - HTTPClient is used to request tokens (default a client with Timeout as its timeout)
+// - Timeout bounds each token request (default 30s)
+// - RefreshBefore is how long before expiry a token is replaced (default 1m)
+// - Now is the clock used to determine expiry (default time.Now)
+type ClientCredentials struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	EndpointParams url.Values
+
+	HTTPClient    *http.Client
+	Timeout       time.Duration
+	RefreshBefore time.Duration
+	Now           func() time.Time
+}
+
+// TokenSource returns a TokenSource that caches the token, requesting a new one when it
+// is about to expire (or when a TokenRoundTripper sees it rejected). Concurrent callers
+// share a single token request.
+func (cc ClientCredentials) TokenSource() TokenSource {
+	if cc.Timeout <= 0 {
+		cc.Timeout = 30 * time.Second
+	}
+
+	if cc.HTTPClient == nil {
+		cc.HTTPClient = &http.Client{Timeout: cc.Timeout}
+	}
+
+	if cc.RefreshBefore <= 0 {
+		cc.RefreshBefore = time.Minute
+	}
+
+	if cc.Now == nil {
+		cc.Now = time.Now
+	}
+
+	return &cachingTokenSource{cc: cc}
+}
+
+type cachingTokenSource struct {
+	cc ClientCredentials
+
+	group singleflight.Group
+	mu    sync.Mutex
+	tok   *Token
+}
+
+// tokenInvalidator is implemented by token sources that cache tokens, so that a
+// TokenRoundTripper can discard a token the server rejected
+type tokenInvalidator interface {
+	invalidate(tok *Token)
+}
+
+var _ tokenInvalidator = (*cachingTokenSource)(nil)
+
+func (s *cachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	tok := s.tok
+	s.mu.Unlock()
+
+	if tok != nil && (tok.Expiry.IsZero() || s.cc.Now().Add(s.cc.RefreshBefore).Before(tok.Expiry)) {
+		return tok, nil
+	}
+
+	// the request is shared, so it is not canceled with the caller that started it
+	ch := s.group.DoChan("token", func() (interface{}, error) {
+		reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cc.Timeout)
+		defer cancel()
+
+		tok, err := s.cc.requestToken(reqCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.tok = tok
+		s.mu.Unlock()
+
+		return tok, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*Token), nil
+	}
+}
+
+// invalidate drops tok if it is still the cached token, so the next call requests a new one
+func (s *cachingTokenSource) invalidate(tok *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok == tok {
+		s.tok = nil
+	}
+}
+
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+func (cc ClientCredentials) requestToken(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	for k, vs := range cc.EndpointParams {
+		form[k] = vs
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", ContentTypeJSON)
+	req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+
+	issued := cc.Now()
+
+	resp, err := cc.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not request token", "token_url", cc.TokenURL)
+	}
+	defer resp.Body.Close() //nolint:errcheck // nothing to do about it
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read token response", "token_url", cc.TokenURL)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil && resp.StatusCode < 300 {
+		return nil, errors.Wrap(err, "could not unmarshal token response", "token_url", cc.TokenURL)
+	}
+
+	if resp.StatusCode >= 300 || tr.AccessToken == "" {
+		return nil, errors.Wrap(ErrTokenRejected, "token request failed",
+			"token_url", cc.TokenURL,
+			"status_code", resp.StatusCode,
+			"oauth_error", tr.Error,
+			"oauth_error_description", tr.ErrorDescription,
+		)
+	}
+
+	tok := &Token{
+		AccessToken: tr.AccessToken,
+		TokenType:   tr.TokenType,
+	}
+
+	if secs, err := strconv.ParseInt(tr.ExpiresIn.String(), 10, 64); err == nil && secs > 0 {
+		tok.Expiry = issued.Add(time.Duration(secs) * time.Second)
+	}
+
+	return tok, nil
+}
+
+// TokenRoundTripper sets the Authorization header of every request from a TokenSource,
+// unless the request already has one or is a redirect to another origin (see
+// IsCrossOriginRedirect). When the response is 401 Unauthorized, a token cached by
+// ClientCredentials.TokenSource is discarded, so that a retry uses a new one.
+type TokenRoundTripper struct {
+	base   http.RoundTripper
+	source TokenSource
+}
+
+var _ http.RoundTripper = (*TokenRoundTripper)(nil)
+
+func NewTokenRoundTripper(base http.RoundTripper, source TokenSource) *TokenRoundTripper {
+	return &TokenRoundTripper{
+		base:   base,
+		source: source,
+	}
+}
+
+func (rt *TokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" || IsCrossOriginRedirect(req) {
+		return rt.base.RoundTrip(req)
+	}
+
+	tok, err := rt.source.Token(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, errors.Wrap(err, "could not get token")
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", tok.AuthorizationHeader())
+
+	resp, err := rt.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		if inv, ok := rt.source.(tokenInvalidator); ok {
+			inv.invalidate(tok)
+		}
+	}
+
+	return resp, err
+}
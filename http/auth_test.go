@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func echoAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+}
+
+func TestAuthOpts(t *testing.T) {
+	t.Parallel()
+
+	srv := echoAuthServer(t)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	body, _, err := c.GetBody(ctx, srv.URL, WithBearerToken("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer abc", string(body))
+
+	body, _, err = c.GetBody(ctx, srv.URL, WithBasicAuth("user", "pass"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", string(body))
+
+	body, _, err = c.GetBody(ctx, srv.URL, WithTokenSource(StaticTokenSource{AccessToken: "xyz", TokenType: "bearer"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer xyz", string(body))
+}
+
+func TestClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	var issued atomic.Int64
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "id" || pass != "secret" {
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":"invalid_client","error_description":"bad secret"}`)
+			return
+		}
+
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = io.WriteString(w, `{"access_token":"tok`+string(rune('0'+n))+`","token_type":"bearer","expires_in":120}`)
+	}))
+	defer tokenSrv.Close()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	ts := ClientCredentials{
+		TokenURL:     tokenSrv.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+		Now:          clock.Now,
+	}.TokenSource()
+
+	apiSrv := echoAuthServer(t)
+	defer apiSrv.Close()
+
+	client := &http.Client{Transport: NewTokenRoundTripper(http.DefaultTransport, ts)}
+	get := func() string {
+		resp, err := client.Get(apiSrv.URL) //nolint:noctx // test
+		assert.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	assert.Equal(t, "Bearer tok1", get())
+	assert.Equal(t, "Bearer tok1", get(), "cached")
+
+	clock.Advance(61 * time.Second)
+	assert.Equal(t, "Bearer tok2", get(), "refreshed within a minute of expiry")
+
+	_, err := ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "wrong"}.TokenSource().Token(context.Background())
+	assert.True(t, errors.Is(err, ErrTokenRejected))
+	assert.Contains(t, err.Error(), "oauth_error=invalid_client")
+}
+
+func TestClientCredentials_concurrent(t *testing.T) {
+	t.Parallel()
+
+	var issued atomic.Int64
+	release := make(chan struct{})
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		issued.Add(1)
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = io.WriteString(w, `{"access_token":"tok","token_type":"bearer","expires_in":120}`)
+	}))
+	defer tokenSrv.Close()
+
+	ts := ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret"}.TokenSource()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := ts.Token(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "waiters honor their own context")
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tok, err := ts.Token(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "tok", tok.AccessToken)
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(1), issued.Load(), "concurrent callers share a single token request")
+}
+
+func TestClientCredentials_timeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer tokenSrv.Close()
+	defer close(release)
+
+	ts := ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret", Timeout: 20 * time.Millisecond}.TokenSource()
+
+	start := time.Now()
+	_, err := ts.Token(context.Background())
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second, "token requests are bounded by the timeout")
+}
+
+func TestClientCredentials_unauthorized(t *testing.T) {
+	t.Parallel()
+
+	var issued atomic.Int64
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = io.WriteString(w, `{"access_token":"tok`+string(rune('0'+n))+`","token_type":"bearer","expires_in":3600}`)
+	}))
+	defer tokenSrv.Close()
+
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer tok1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer apiSrv.Close()
+
+	ts := ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret"}.TokenSource()
+	client := &http.Client{Transport: NewTokenRoundTripper(http.DefaultTransport, ts)}
+
+	resp, err := client.Get(apiSrv.URL) //nolint:noctx // test
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = client.Get(apiSrv.URL) //nolint:noctx // test
+	assert.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck // test
+
+	b, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer tok2", string(b), "a rejected token is not reused")
+	assert.Equal(t, int64(2), issued.Load())
+}
+
+func TestHMACRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	secrets := map[string][]byte{"k1": []byte("s3cret")}
+	lookup := func(keyID string) ([]byte, bool) {
+		s, ok := secrets[keyID]
+		return s, ok
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, err := VerifyHMAC(r, lookup, time.Minute)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, err.Error())
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, keyID+":"+string(body))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewHMACRoundTripper(http.DefaultTransport, "k1", []byte("s3cret"))}
+
+	resp, err := client.Post(srv.URL+"/things?x=1", ContentTypeJSON, strings.NewReader(`{"a":1}`)) //nolint:noctx // test
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, `k1:{"a":1}`, string(body))
+
+	bad := &http.Client{Transport: NewHMACRoundTripper(http.DefaultTransport, "k1", []byte("wrong"))}
+	resp, err = bad.Get(srv.URL) //nolint:noctx // test
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	_, err = VerifyHMAC(req, lookup, time.Minute)
+	assert.True(t, errors.Is(err, ErrSignatureMissing))
+}
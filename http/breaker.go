@@ -0,0 +1,369 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/logging/level"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// ErrCircuitOpen is matched (with errors.Is) by a *CircuitOpenError
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of the circuit breaker for a single host
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through, counting failures
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests until the cool-down has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of trial requests through
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerOptions configures the circuit breakers of a TelemeterClient. The breaker for a
+// host opens when either threshold is reached, and a retried request counts once.
+//
+// - ConsecutiveFailures trips the breaker after that many failures in a row (0 disables;
+// defaults to 5 if FailureRatio is also 0)
+// - FailureRatio trips the breaker when the ratio of failures within the current Window
+// reaches it, once at least MinRequests have been made (0 disables)
+// - Window is how often the closed-state counts are reset (0 never resets them)
+// - CoolDown is how long the breaker stays open before allowing trial requests (default 30s)
+// - HalfOpenRequests is how many trial requests must succeed to close the breaker (default 1)
+// - IsFailure classifies the outcome of a request (default DefaultBreakerFailure)
+// - Now is the clock used by the breakers (default time.Now)
+type BreakerOptions struct {
+	ConsecutiveFailures int
+	FailureRatio        float64
+	MinRequests         int
+	Window              time.Duration
+	CoolDown            time.Duration
+	HalfOpenRequests    int
+	IsFailure           func(resp *http.Response, err error) bool
+	Now                 func() time.Time
+}
+
+// DefaultBreakerFailure treats errors, 429 responses, and 5xx responses as failures. Requests
+// canceled by the caller (including losing hedges) say nothing about the host, so are not.
+func DefaultBreakerFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500)
+}
+
+// CircuitOpenError is returned without making a request when the breaker for Host is open
+type CircuitOpenError struct {
+	Host string
+	// RetryAfter is how long until the breaker will allow a trial request (or, while the
+	// trial requests of a half-open breaker are in flight, the cool-down)
+	RetryAfter time.Duration
+}
+
+var _ errors.Error = (*CircuitOpenError)(nil)
+
+func (e *CircuitOpenError) Msg() string {
+	return ErrCircuitOpen.Error()
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: host=%s retry_after=%s", e.Msg(), e.Host, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Data() []interface{} {
+	return []interface{}{"host", e.Host, "retry_after", e.RetryAfter}
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return nil
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// breakers holds a circuit breaker per host
+type breakers struct {
+	opts    BreakerOptions
+	logger  logging.Logger
+	counter telemetry.Int64Counter
+	mu      sync.Mutex
+	byHost  map[string]*breaker
+	sweepAt int // the number of breakers at which idle ones are evicted
+}
+
+// minBreakerSweep is the smallest number of breakers at which idle ones are evicted
+const minBreakerSweep = 64
+
+type breaker struct {
+	state      BreakerState
+	generation uint64
+	expiry     time.Time // end of the window when closed, or of the cool-down when open
+
+	requests            int
+	failures            int
+	consecutiveFailures int
+	halfOpenInFlight    int
+	halfOpenSuccesses   int
+	inFlight            int // across generations, for eviction
+}
+
+// idle reports whether br holds nothing worth keeping, so it can be evicted
+func (br *breaker) idle() bool {
+	return br.state == BreakerClosed && br.inFlight == 0 && br.failures == 0 && br.consecutiveFailures == 0
+}
+
+func newBreakers(opts BreakerOptions, logger logging.Logger, tel *telemetry.Telemeter) *breakers {
+	if opts.ConsecutiveFailures <= 0 && opts.FailureRatio <= 0 {
+		opts.ConsecutiveFailures = 5
+	}
+
+	if opts.CoolDown <= 0 {
+		opts.CoolDown = 30 * time.Second
+	}
+
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+
+	if opts.IsFailure == nil {
+		opts.IsFailure = DefaultBreakerFailure
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	b := &breakers{
+		opts:    opts,
+		logger:  logger,
+		byHost:  map[string]*breaker{},
+		sweepAt: minBreakerSweep,
+	}
+
+	counter, err := tel.Meter("http").Int64Counter(
+		"http.client.circuit_breaker.transitions",
+		telemetry.WithDescription("Number of circuit breaker state changes"),
+	)
+	if err != nil {
+		level.Error(logger).Err("could not create circuit breaker counter", err)
+	}
+	b.counter = counter
+
+	return b
+}
+
+// allow reports whether a request to host may proceed. If so, done must be called with
+// the outcome of the request.
+func (b *breakers) allow(host string) (done func(*http.Response, error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.opts.Now()
+
+	br, ok := b.byHost[host]
+	if !ok {
+		if len(b.byHost) >= b.sweepAt {
+			b.evictIdle(now)
+		}
+
+		br = &breaker{}
+		b.byHost[host] = br
+		br.reset(BreakerClosed, now, b.opts)
+	}
+
+	b.refresh(host, br, now)
+
+	switch br.state {
+	case BreakerOpen:
+		return nil, &CircuitOpenError{Host: host, RetryAfter: br.expiry.Sub(now)}
+	case BreakerHalfOpen:
+		if br.halfOpenInFlight+br.halfOpenSuccesses >= b.opts.HalfOpenRequests {
+			return nil, &CircuitOpenError{Host: host, RetryAfter: b.opts.CoolDown}
+		}
+		br.halfOpenInFlight++
+	}
+
+	br.inFlight++
+	generation := br.generation
+
+	return func(resp *http.Response, reqErr error) {
+		failed := b.opts.IsFailure(resp, reqErr)
+		if !failed && errors.Is(reqErr, context.Canceled) {
+			b.abandon(host, br, generation)
+			return
+		}
+
+		b.record(host, br, generation, failed)
+	}, nil
+}
+
+// stateOf is the current state of the breaker for host
+func (b *breakers) stateOf(host string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.byHost[host]
+	if !ok {
+		return BreakerClosed
+	}
+
+	b.refresh(host, br, b.opts.Now())
+
+	return br.state
+}
+
+func (b *breakers) record(host string, br *breaker, generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.opts.Now()
+
+	br.inFlight--
+	b.refresh(host, br, now)
+
+	if br.generation != generation {
+		return // the outcome belongs to a previous state
+	}
+
+	switch br.state {
+	case BreakerClosed:
+		br.requests++
+		if !failed {
+			br.consecutiveFailures = 0
+			return
+		}
+
+		br.failures++
+		br.consecutiveFailures++
+		if b.shouldTrip(br) {
+			b.transition(host, br, BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		br.halfOpenInFlight--
+		if failed {
+			b.transition(host, br, BreakerOpen, now)
+			return
+		}
+
+		br.halfOpenSuccesses++
+		if br.halfOpenSuccesses >= b.opts.HalfOpenRequests {
+			b.transition(host, br, BreakerClosed, now)
+		}
+	}
+}
+
+// abandon releases the slot of a request whose outcome is not counted, e.g. because it
+// was canceled
+func (b *breakers) abandon(host string, br *breaker, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br.inFlight--
+	b.refresh(host, br, b.opts.Now())
+
+	if br.generation == generation && br.state == BreakerHalfOpen {
+		br.halfOpenInFlight--
+	}
+}
+
+// evictIdle removes the idle breakers, so that a client talking to many hosts does not
+// keep a breaker for each of them forever
+func (b *breakers) evictIdle(now time.Time) {
+	for host, br := range b.byHost {
+		b.refresh(host, br, now)
+		if br.idle() {
+			delete(b.byHost, host)
+		}
+	}
+
+	b.sweepAt = max(minBreakerSweep, 2*len(b.byHost))
+}
+
+func (b *breakers) shouldTrip(br *breaker) bool {
+	if b.opts.ConsecutiveFailures > 0 && br.consecutiveFailures >= b.opts.ConsecutiveFailures {
+		return true
+	}
+
+	if b.opts.FailureRatio > 0 && br.requests >= max(b.opts.MinRequests, 1) {
+		return float64(br.failures)/float64(br.requests) >= b.opts.FailureRatio
+	}
+
+	return false
+}
+
+// refresh moves br along based on the time: resetting the closed window, or moving
+// from open to half-open once the cool-down has passed
+func (b *breakers) refresh(host string, br *breaker, now time.Time) {
+	if br.expiry.IsZero() || now.Before(br.expiry) {
+		return
+	}
+
+	switch br.state {
+	case BreakerClosed:
+		br.reset(BreakerClosed, now, b.opts)
+	case BreakerOpen:
+		b.transition(host, br, BreakerHalfOpen, now)
+	}
+}
+
+func (b *breakers) transition(host string, br *breaker, to BreakerState, now time.Time) {
+	from := br.state
+	br.reset(to, now, b.opts)
+
+	logger := logging.With(b.logger, "host", host, "from", from.String(), "to", to.String())
+	if to == BreakerOpen {
+		level.Warn(logger).Message("circuit breaker state changed")
+	} else {
+		level.Info(logger).Message("circuit breaker state changed")
+	}
+
+	if b.counter != nil {
+		b.counter.Add(context.Background(), 1, telemetry.WithMetricAttributes(
+			telemetry.KVString("host", host),
+			telemetry.KVString("from", from.String()),
+			telemetry.KVString("to", to.String()),
+		))
+	}
+}
+
+func (br *breaker) reset(state BreakerState, now time.Time, opts BreakerOptions) {
+	br.state = state
+	br.generation++
+	br.requests = 0
+	br.failures = 0
+	br.consecutiveFailures = 0
+	br.halfOpenInFlight = 0
+	br.halfOpenSuccesses = 0
+
+	switch {
+	case state == BreakerOpen:
+		br.expiry = now.Add(opts.CoolDown)
+	case state == BreakerClosed && opts.Window > 0:
+		br.expiry = now.Add(opts.Window)
+	default:
+		br.expiry = time.Time{}
+	}
+}
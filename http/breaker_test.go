@@ -0,0 +1,217 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestTelemeterClient_circuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	host := u.Host
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+
+	c := newTestClient(t)
+	c.SetCircuitBreaker(BreakerOptions{
+		ConsecutiveFailures: 3,
+		CoolDown:            time.Minute,
+		Now:                 clock.Now,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, _, err = c.GetBody(ctx, srv.URL)
+		assert.True(t, IsServerError(err))
+	}
+	assert.Equal(t, BreakerOpen, c.CircuitState(host))
+
+	_, _, err = c.GetBody(ctx, srv.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	var openErr *CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, host, openErr.Host)
+	assert.Equal(t, time.Minute, openErr.RetryAfter)
+	assert.Equal(t, int64(3), calls.Load(), "no request was made while open")
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, c.CircuitState(host))
+
+	_, _, err = c.GetBody(ctx, srv.URL)
+	assert.True(t, IsServerError(err))
+	assert.Equal(t, BreakerOpen, c.CircuitState(host), "a failed trial reopens the breaker")
+
+	clock.Advance(time.Minute)
+	healthy.Store(true)
+
+	_, _, err = c.GetBody(ctx, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakerClosed, c.CircuitState(host))
+	assert.Equal(t, BreakerClosed, c.CircuitState("other.example.com"))
+}
+
+func TestBreakers_failureRatio(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := newBreakers(BreakerOptions{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       10 * time.Second,
+		Now:          clock.Now,
+	}, logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+
+	ok := &http.Response{StatusCode: http.StatusOK}
+	bad := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	request := func(resp *http.Response) {
+		done, err := b.allow("h")
+		assert.NoError(t, err)
+		done(resp, nil)
+	}
+
+	request(bad)
+	request(bad)
+	request(ok)
+	assert.Equal(t, BreakerClosed, b.stateOf("h"), "below MinRequests")
+
+	clock.Advance(10 * time.Second)
+	request(bad)
+	request(ok)
+	request(ok)
+	request(bad)
+	assert.Equal(t, BreakerOpen, b.stateOf("h"), "counts reset with the window")
+}
+
+func TestBreakers_staleOutcome(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := newBreakers(BreakerOptions{ConsecutiveFailures: 1, Now: clock.Now}, logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+
+	slow, err := b.allow("h")
+	assert.NoError(t, err)
+
+	fast, err := b.allow("h")
+	assert.NoError(t, err)
+	fast(nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerOpen, b.stateOf("h"))
+
+	slow(&http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, BreakerOpen, b.stateOf("h"), "outcomes from before the breaker opened are ignored")
+}
+
+func TestBreakers_canceled(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := newBreakers(BreakerOptions{ConsecutiveFailures: 1, CoolDown: time.Second, Now: clock.Now}, logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+
+	done, err := b.allow("h")
+	assert.NoError(t, err)
+	done(nil, errors.Wrap(context.Canceled, "request canceled"))
+	assert.Equal(t, BreakerClosed, b.stateOf("h"), "canceled requests are not failures")
+
+	done, err = b.allow("h")
+	assert.NoError(t, err)
+	done(nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerOpen, b.stateOf("h"))
+
+	clock.Advance(time.Second)
+	trial, err := b.allow("h")
+	assert.NoError(t, err)
+	trial(nil, context.Canceled)
+	assert.Equal(t, BreakerHalfOpen, b.stateOf("h"), "a canceled trial does not close the breaker")
+
+	trial, err = b.allow("h")
+	assert.NoError(t, err, "a canceled trial releases its slot")
+	trial(&http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, BreakerClosed, b.stateOf("h"))
+}
+
+func TestBreakers_evictIdle(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := newBreakers(BreakerOptions{ConsecutiveFailures: 1, CoolDown: time.Second, Now: clock.Now}, logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+
+	done, err := b.allow("failing")
+	assert.NoError(t, err)
+	done(nil, errors.New("connection refused"))
+
+	inFlight, err := b.allow("in-flight")
+	assert.NoError(t, err)
+
+	for i := range 10 * minBreakerSweep {
+		done, err := b.allow(fmt.Sprintf("host-%d", i))
+		assert.NoError(t, err)
+		done(&http.Response{StatusCode: http.StatusOK}, nil)
+	}
+
+	b.mu.Lock()
+	size := len(b.byHost)
+	b.mu.Unlock()
+	assert.LessOrEqual(t, size, 2*minBreakerSweep, "idle breakers are evicted")
+
+	assert.Equal(t, BreakerOpen, b.stateOf("failing"), "breakers with failures are kept")
+
+	inFlight(nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerOpen, b.stateOf("in-flight"), "breakers with requests in flight are kept")
+
+	clock.Advance(time.Second)
+	trial, err := b.allow("failing")
+	assert.NoError(t, err)
+	defer trial(&http.Response{StatusCode: http.StatusOK}, nil)
+
+	_, err = b.allow("failing")
+	var openErr *CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, time.Second, openErr.RetryAfter, "a half-open breaker waits out the cool-down")
+}
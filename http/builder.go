@@ -0,0 +1,217 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// Middleware wraps a RoundTripper to add behavior to every request attempt
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper, for writing Middleware
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// UserAgent builds a User-Agent value of the form "appName/buildVersion (go1.x)", suitable
+// for the build version passed to cli.NewCLI. If buildVersion is empty, the version of the
+// main module is used, if known.
+func UserAgent(appName, buildVersion string) string {
+	if buildVersion == "" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+			buildVersion = info.Main.Version
+		} else {
+			buildVersion = "unknown"
+		}
+	}
+
+	return fmt.Sprintf("%s/%s (%s)", appName, buildVersion, runtime.Version())
+}
+
+// ClientBuilder configures a TelemeterClient with settings shared by all of its requests
+type ClientBuilder struct {
+	logger     logging.Logger
+	tel        *telemetry.Telemeter
+	spanOpts   []telemetry.StartSpanOption
+	baseURL    string
+	header     http.Header
+	query      url.Values
+	userAgent  string
+	middleware []Middleware
+	retries    *RetryOptions
+	transport  *TransportOptions
+}
+
+// NewClientBuilder starts building a TelemeterClient
+func NewClientBuilder(logger logging.Logger, tel *telemetry.Telemeter) *ClientBuilder {
+	return &ClientBuilder{
+		logger: logger,
+		tel:    tel,
+		header: http.Header{},
+		query:  url.Values{},
+	}
+}
+
+// SpanOptions are used for the spans of each request
+func (b *ClientBuilder) SpanOptions(opts ...telemetry.StartSpanOption) *ClientBuilder {
+	b.spanOpts = append(b.spanOpts, opts...)
+	return b
+}
+
+// BaseURL is resolved against the URL of each request, so that requests can use relative
+// paths. A path with a leading slash replaces the path of the base URL, and one without is
+// relative to it (the base path is treated as a directory, ending with a slash).
+func (b *ClientBuilder) BaseURL(base string) *ClientBuilder {
+	b.baseURL = base
+	return b
+}
+
+// Header adds a default header value, used for requests that do not set that header
+func (b *ClientBuilder) Header(key string, values ...string) *ClientBuilder {
+	for _, v := range values {
+		b.header.Add(key, v)
+	}
+
+	return b
+}
+
+// Query adds a default query parameter value, used for requests that do not set that parameter
+func (b *ClientBuilder) Query(key string, values ...string) *ClientBuilder {
+	for _, v := range values {
+		b.query.Add(key, v)
+	}
+
+	return b
+}
+
+// UserAgent sets the default User-Agent header (see UserAgent)
+func (b *ClientBuilder) UserAgent(appName, buildVersion string) *ClientBuilder {
+	b.userAgent = UserAgent(appName, buildVersion)
+	return b
+}
+
+// Use adds middleware around the transport, which see each attempt (including retries) as
+// it is sent to the network. The first middleware added is the outermost.
+func (b *ClientBuilder) Use(middleware ...Middleware) *ClientBuilder {
+	b.middleware = append(b.middleware, middleware...)
+	return b
+}
+
+// Transport sets the options of the underlying transport (see NewTransport)
+func (b *ClientBuilder) Transport(opts TransportOptions) *ClientBuilder {
+	b.transport = &opts
+	return b
+}
+
+// Retries sets the default retry settings (see TelemeterClient.ConfigureRetries)
+func (b *ClientBuilder) Retries(opts RetryOptions) *ClientBuilder {
+	b.retries = &opts
+	return b
+}
+
+// Build creates the TelemeterClient
+func (b *ClientBuilder) Build() (*TelemeterClient, error) {
+	var base http.RoundTripper
+	if b.transport != nil {
+		t, err := NewTransport(*b.transport)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create transport")
+		}
+		base = t
+	}
+
+	c := NewTelemeterClientWithTransport(b.logger, b.tel, base, b.spanOpts...)
+
+	if b.baseURL != "" {
+		u, err := url.Parse(b.baseURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse base url", "base_url", b.baseURL)
+		}
+
+		if !u.IsAbs() {
+			return nil, errors.WithDetails(errors.New("base url must be absolute"), "base_url", b.baseURL)
+		}
+
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+			if u.RawPath != "" {
+				u.RawPath += "/"
+			}
+		}
+
+		c.baseURL = u
+	}
+
+	c.defaultHeader = b.header.Clone()
+	if b.userAgent != "" {
+		c.defaultHeader.Set("User-Agent", b.userAgent)
+	}
+	c.defaultQuery = b.query
+
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		c.client.HTTPClient.Transport = b.middleware[i](c.client.HTTPClient.Transport)
+	}
+
+	if b.retries != nil {
+		c.ConfigureRetries(*b.retries)
+	}
+
+	return c, nil
+}
+
+// resolveURL resolves reqURL against the client's base URL, if any
+func (c *TelemeterClient) resolveURL(reqURL string) (string, error) {
+	if c.baseURL == nil {
+		return reqURL, nil
+	}
+
+	ref, err := url.Parse(reqURL)
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse request url", "url", reqURL)
+	}
+
+	return c.baseURL.ResolveReference(ref).String(), nil
+}
+
+// applyDefaults adds the client's default headers and query parameters to req, where it
+// does not already have them
+func (c *TelemeterClient) applyDefaults(req *Request) {
+	for k, vs := range c.defaultHeader {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = append([]string(nil), vs...)
+		}
+	}
+
+	if len(c.defaultQuery) == 0 {
+		return
+	}
+
+	// only the missing parameters are encoded, so the query of req is left as it was
+	query := req.URL.Query()
+	missing := url.Values{}
+	for k, vs := range c.defaultQuery {
+		if !query.Has(k) {
+			missing[k] = vs
+		}
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	if req.URL.RawQuery == "" {
+		req.URL.RawQuery = missing.Encode()
+	} else {
+		req.URL.RawQuery += "&" + missing.Encode()
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+func TestClientBuilder(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, strings.Join([]string{
+			r.URL.Path,
+			r.URL.RawQuery,
+			r.Header.Get("X-Team"),
+			r.Header.Get("User-Agent"),
+			r.Header.Get("X-Middleware"),
+		}, "|"))
+	}))
+	defer srv.Close()
+
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+
+				// a RoundTripper must not modify the request it is given
+				req = req.Clone(req.Context())
+				req.Header.Add("X-Middleware", name)
+
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	zero := 0
+	c, err := NewClientBuilder(logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t)).
+		BaseURL(srv.URL+"/api/v1").
+		Header("X-Team", "core").
+		Query("version", "2").
+		UserAgent("tester", "1.2.3").
+		Use(mw("outer"), mw("inner")).
+		Retries(RetryOptions{RetryMax: &zero}).
+		Build()
+	assert.NoError(t, err)
+
+	body, _, err := c.GetBody(context.Background(), "users/42?expand=true")
+	assert.NoError(t, err)
+
+	parts := strings.Split(string(body), "|")
+	assert.Equal(t, "/api/v1/users/42", parts[0])
+	assert.Equal(t, "expand=true&version=2", parts[1])
+	assert.Equal(t, "core", parts[2])
+	assert.True(t, strings.HasPrefix(parts[3], "tester/1.2.3 (go"), parts[3])
+	assert.Equal(t, "outer", parts[4])
+	assert.Equal(t, []string{"outer", "inner"}, order)
+
+	body, _, err = c.GetBody(context.Background(), "/health?version=3", WithHeaders(http.Header{"X-Team": {"other"}}))
+	assert.NoError(t, err)
+
+	parts = strings.Split(string(body), "|")
+	assert.Equal(t, "/health", parts[0], "absolute paths replace the base path")
+	assert.Equal(t, "version=3", parts[1], "request parameters override the defaults")
+	assert.Equal(t, "other", parts[2], "request headers override the defaults")
+
+	body, _, err = c.GetBody(context.Background(), "/search?z=1&a=b%2Fc&q=a+b")
+	assert.NoError(t, err)
+
+	parts = strings.Split(string(body), "|")
+	assert.Equal(t, "z=1&a=b%2Fc&q=a+b&version=2", parts[1], "the request query is not re-encoded")
+
+	_, err = NewClientBuilder(logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t)).BaseURL("/relative").Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,436 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// CacheStatusHeader is set on every GET response passing through a CachingRoundTripper
+const CacheStatusHeader = "X-Cache-Status"
+
+// Values of CacheStatusHeader, and of the "http.cache.status" span attribute
+const (
+	// CacheHit is a fresh response served from the cache without a request
+	CacheHit = "hit"
+	// CacheRevalidated is a stale response served from the cache after a 304 Not Modified
+	CacheRevalidated = "revalidated"
+	// CacheMiss is a response from the server
+	CacheMiss = "miss"
+	// CacheBypass is a response from the server that the cache did not consider
+	CacheBypass = "bypass"
+)
+
+// CacheOpt configures a CachingRoundTripper
+type CacheOpt func(*CachingRoundTripper)
+
+// WithMaxCacheableBody sets the largest response body that will be cached (default 1MiB)
+func WithMaxCacheableBody(n int64) CacheOpt {
+	return func(rt *CachingRoundTripper) {
+		rt.maxBody = n
+	}
+}
+
+// WithCacheClock sets the clock used to determine freshness (default time.Now)
+func WithCacheClock(now func() time.Time) CacheOpt {
+	return func(rt *CachingRoundTripper) {
+		rt.now = now
+	}
+}
+
+// CachingRoundTripper is a private HTTP cache for GET requests. It honors the
+// Cache-Control (max-age, no-cache, no-store), Expires, and Vary headers, revalidates
+// stale responses with If-None-Match and If-Modified-Since, and invalidates a URL after
+// a successful unsafe request to it. Responses to requests with credentials (the
+// Authorization or Cookie headers) are only served to requests with the same credentials,
+// and an unsafe request invalidates the URL for every set of credentials.
+type CachingRoundTripper struct {
+	base    http.RoundTripper
+	storage CacheStorage
+	maxBody int64
+	now     func() time.Time
+
+	// variants holds the credentialed keys stored for each URL, so that they can
+	// all be invalidated together
+	mu       sync.Mutex
+	variants map[string]map[string]struct{}
+}
+
+var _ http.RoundTripper = (*CachingRoundTripper)(nil)
+
+func NewCachingRoundTripper(base http.RoundTripper, storage CacheStorage, opts ...CacheOpt) *CachingRoundTripper {
+	rt := &CachingRoundTripper{
+		base:    base,
+		storage: storage,
+		maxBody: 1 << 20,
+		now:     time.Now,
+
+		variants: map[string]map[string]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	return rt
+}
+
+// CacheStatus is the CacheStatusHeader of resp, or "" if it did not pass through a cache
+func CacheStatus(resp *http.Response) string {
+	return resp.Header.Get(CacheStatusHeader)
+}
+
+func (rt *CachingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := rt.base.RoundTrip(req)
+		if err == nil && isUnsafeMethod(req.Method) && resp.StatusCode < 400 {
+			rt.invalidate(req.URL.String())
+		}
+
+		return resp, err
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if _, noStore := reqCC["no-store"]; noStore || hasConditionalHeaders(req) || req.Header.Get("Range") != "" {
+		return rt.fetch(req, CacheBypass, false)
+	}
+
+	key := cacheKey(req)
+
+	cached, ok := rt.storage.Get(key)
+	if !ok {
+		rt.forget(req.URL.String(), key)
+	}
+	if !ok || !varyMatches(cached, req) {
+		return rt.fetch(req, CacheMiss, true)
+	}
+
+	now := rt.now()
+	if now.Before(cached.FreshUntil) && !requiresRevalidation(reqCC) {
+		return rt.cachedResponse(req, cached, CacheHit, now), nil
+	}
+
+	etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return rt.fetch(req, CacheMiss, true)
+	}
+
+	condReq := req.Clone(req.Context())
+	if etag != "" {
+		condReq.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		condReq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := rt.base.RoundTrip(condReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusNotModified {
+		resp.Request = req
+		return rt.store(req, resp, CacheMiss)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	refreshed := *cached
+	refreshed.Header = cached.Header.Clone()
+	for k, vs := range resp.Header {
+		refreshed.Header[k] = vs
+	}
+	now = rt.now()
+	refreshed.StoredAt = now
+	refreshed.FreshUntil = now.Add(freshnessLifetime(refreshed.Header, now))
+	rt.set(req, key, &refreshed)
+
+	return rt.cachedResponse(req, &refreshed, CacheRevalidated, now), nil
+}
+
+func (rt *CachingRoundTripper) fetch(req *http.Request, status string, storable bool) (*http.Response, error) {
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !storable {
+		markCacheStatus(req, resp, status)
+		return resp, nil
+	}
+
+	return rt.store(req, resp, status)
+}
+
+// store caches resp if it may be, returning a response with an unread body
+func (rt *CachingRoundTripper) store(req *http.Request, resp *http.Response, status string) (*http.Response, error) {
+	defer markCacheStatus(req, resp, status)
+
+	if !isStorable(resp) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, rt.maxBody+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, errors.Wrap(err, "could not read response body")
+	}
+
+	if int64(len(body)) > rt.maxBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := rt.now()
+	rt.set(req, cacheKey(req), &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		VaryHeader: varyHeader(resp, req),
+		StoredAt:   now,
+		FreshUntil: now.Add(freshnessLifetime(resp.Header, now)),
+	})
+
+	return resp, nil
+}
+
+// set stores resp under key, recording key as a variant of the URL of req
+func (rt *CachingRoundTripper) set(req *http.Request, key string, resp *CachedResponse) {
+	if u := req.URL.String(); key != u {
+		rt.mu.Lock()
+		keys, ok := rt.variants[u]
+		if !ok {
+			keys = map[string]struct{}{}
+			rt.variants[u] = keys
+		}
+		keys[key] = struct{}{}
+		rt.mu.Unlock()
+	}
+
+	rt.storage.Set(key, resp)
+}
+
+// forget drops key from the variants of u once the storage no longer holds it
+func (rt *CachingRoundTripper) forget(u, key string) {
+	if key == u {
+		return
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if keys, ok := rt.variants[u]; ok {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(rt.variants, u)
+		}
+	}
+}
+
+// invalidate deletes every stored response for u, whatever its credentials
+func (rt *CachingRoundTripper) invalidate(u string) {
+	rt.mu.Lock()
+	keys := rt.variants[u]
+	delete(rt.variants, u)
+	rt.mu.Unlock()
+
+	rt.storage.Delete(u)
+	for key := range keys {
+		rt.storage.Delete(key)
+	}
+}
+
+func (rt *CachingRoundTripper) cachedResponse(req *http.Request, cached *CachedResponse, status string, now time.Time) *http.Response {
+	resp := &http.Response{
+		Status:        strconv.Itoa(cached.StatusCode) + " " + http.StatusText(cached.StatusCode),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+
+	resp.Header.Set("Age", strconv.FormatInt(int64(max(now.Sub(cached.StoredAt), 0)/time.Second)+headerSeconds(cached.Header, "Age"), 10))
+	markCacheStatus(req, resp, status)
+
+	return resp
+}
+
+func markCacheStatus(req *http.Request, resp *http.Response, status string) {
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set(CacheStatusHeader, status)
+
+	telemetry.SpanFromContext(req.Context()).SetAttributes(telemetry.KVString("http.cache.status", status))
+}
+
+// cacheKey is the URL of req, along with a hash of its credentials, if any, so that
+// responses are not shared between callers with different credentials
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	var found bool
+	for _, name := range []string{"Authorization", "Cookie"} {
+		for _, v := range req.Header.Values(name) {
+			_, _ = io.WriteString(h, name+": "+v+"\n")
+			found = true
+		}
+	}
+
+	if !found {
+		return req.URL.String()
+	}
+
+	return req.URL.String() + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	default:
+		return true
+	}
+}
+
+func hasConditionalHeaders(req *http.Request) bool {
+	for _, h := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		if req.Header.Get(h) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func requiresRevalidation(reqCC map[string]string) bool {
+	if _, ok := reqCC["no-cache"]; ok {
+		return true
+	}
+
+	return reqCC["max-age"] == "0"
+}
+
+// isStorable reports whether resp may be stored, following the cacheable status codes
+// and response directives
+func isStorable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+
+	if resp.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	_, noStore := parseCacheControl(resp.Header)["no-store"]
+
+	return !noStore
+}
+
+// freshnessLifetime is how long a response with the provided headers remains fresh, less
+// its age; responses with no-cache (or no freshness information) are immediately stale
+func freshnessLifetime(h http.Header, now time.Time) time.Duration {
+	cc := parseCacheControl(h)
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		date = now
+	}
+
+	var lifetime time.Duration
+	if maxAge, ok := cc["max-age"]; ok {
+		secs, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return 0
+		}
+		lifetime = time.Duration(secs) * time.Second
+	} else if expires := h.Get("Expires"); expires != "" {
+		exp, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		lifetime = exp.Sub(date)
+	} else if lm, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		lifetime = date.Sub(lm) / 10 // heuristic freshness
+	}
+
+	age := max(now.Sub(date), 0) + time.Duration(headerSeconds(h, "Age"))*time.Second
+
+	return max(lifetime-age, 0)
+}
+
+func headerSeconds(h http.Header, name string) int64 {
+	secs, err := strconv.ParseInt(h.Get(name), 10, 64)
+	if err != nil || secs < 0 {
+		return 0
+	}
+
+	return secs
+}
+
+// parseCacheControl parses the Cache-Control header into lower-cased directives
+func parseCacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return cc
+}
+
+func varyHeader(resp *http.Response, req *http.Request) http.Header {
+	vary := http.Header{}
+	for _, line := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = http.CanonicalHeaderKey(strings.TrimSpace(name)); name != "" {
+				vary[name] = req.Header.Values(name)
+			}
+		}
+	}
+
+	return vary
+}
+
+func varyMatches(cached *CachedResponse, req *http.Request) bool {
+	for name, vs := range cached.VaryHeader {
+		if strings.Join(vs, ",") != strings.Join(req.Header.Values(name), ",") {
+			return false
+		}
+	}
+
+	return true
+}
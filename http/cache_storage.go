@@ -0,0 +1,135 @@
+package http
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by a CachingRoundTripper
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// VaryHeader holds the request headers named by the response's Vary header
+	VaryHeader http.Header
+	// StoredAt is when the response was received (or last revalidated)
+	StoredAt time.Time
+	// FreshUntil is when the response becomes stale
+	FreshUntil time.Time
+}
+
+// Size is the approximate memory used by the response
+func (r *CachedResponse) Size() int64 {
+	size := int64(len(r.Body))
+	for _, h := range []http.Header{r.Header, r.VaryHeader} {
+		for k, vs := range h {
+			size += int64(len(k))
+			for _, v := range vs {
+				size += int64(len(v))
+			}
+		}
+	}
+
+	return size
+}
+
+// CacheStorage stores the responses of a CachingRoundTripper; it must be safe for concurrent use
+type CacheStorage interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+	Delete(key string)
+}
+
+// LRUCacheStorage is an in-memory CacheStorage that evicts the least recently used
+// responses once it holds more than maxEntries responses or maxBytes of responses
+type LRUCacheStorage struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	size    int64
+}
+
+type lruEntry struct {
+	key  string
+	resp *CachedResponse
+	size int64
+}
+
+var _ CacheStorage = (*LRUCacheStorage)(nil)
+
+// NewLRUCacheStorage creates an LRUCacheStorage; a limit of 0 is unlimited
+func NewLRUCacheStorage(maxEntries int, maxBytes int64) *LRUCacheStorage {
+	return &LRUCacheStorage{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (s *LRUCacheStorage) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.order.MoveToFront(el)
+
+	return el.Value.(*lruEntry).resp, true
+}
+
+// Set stores resp, unless it is larger than maxBytes by itself
+func (s *LRUCacheStorage) Set(key string, resp *CachedResponse) {
+	size := resp.Size()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+
+	if s.maxBytes > 0 && size > s.maxBytes {
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, resp: resp, size: size})
+	s.size += size
+
+	for (s.maxEntries > 0 && s.order.Len() > s.maxEntries) || (s.maxBytes > 0 && s.size > s.maxBytes) {
+		s.remove(s.order.Back().Value.(*lruEntry).key)
+	}
+}
+
+func (s *LRUCacheStorage) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+}
+
+// Len is the number of stored responses
+func (s *LRUCacheStorage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+func (s *LRUCacheStorage) remove(key string) {
+	el, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	s.order.Remove(el)
+	delete(s.entries, key)
+	s.size -= el.Value.(*lruEntry).size
+}
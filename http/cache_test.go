@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	var hits, notModified atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/private":
+			w.Header().Set("Cache-Control", "max-age=600")
+			_, _ = io.WriteString(w, "body:"+r.URL.Path+":"+r.Header.Get("Authorization"))
+			return
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		}
+
+		_, _ = io.WriteString(w, "body:"+r.URL.Path+":"+r.Header.Get("Accept-Language"))
+	}))
+	defer srv.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	storage := NewLRUCacheStorage(10, 0)
+	client := &http.Client{Transport: NewCachingRoundTripper(http.DefaultTransport, storage, WithCacheClock(clock.Now))}
+
+	get := func(path string, header ...string) (string, string) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+path, http.NoBody)
+		assert.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return string(body), CacheStatus(resp)
+	}
+
+	body, status := get("/fresh")
+	assert.Equal(t, "body:/fresh:", body)
+	assert.Equal(t, CacheMiss, status)
+
+	body, status = get("/fresh")
+	assert.Equal(t, "body:/fresh:", body)
+	assert.Equal(t, CacheHit, status)
+	assert.Equal(t, int64(1), hits.Load())
+
+	_, status = get("/fresh", "Cache-Control", "no-cache")
+	assert.Equal(t, CacheMiss, status, "no validators, so refetched")
+
+	clock.Advance(2 * time.Minute)
+	_, status = get("/fresh")
+	assert.Equal(t, CacheMiss, status, "stale")
+
+	hits.Store(0)
+	_, status = get("/etag")
+	assert.Equal(t, CacheMiss, status)
+	body, status = get("/etag")
+	assert.Equal(t, "body:/etag:", body)
+	assert.Equal(t, CacheRevalidated, status)
+	assert.Equal(t, int64(1), notModified.Load())
+
+	_, status = get("/nostore")
+	assert.Equal(t, CacheMiss, status)
+	_, status = get("/nostore")
+	assert.Equal(t, CacheMiss, status)
+
+	body, _ = get("/vary", "Accept-Language", "en")
+	assert.Equal(t, "body:/vary:en", body)
+	body, status = get("/vary", "Accept-Language", "fr")
+	assert.Equal(t, "body:/vary:fr", body)
+	assert.Equal(t, CacheMiss, status)
+
+	body, status = get("/private", "Authorization", "Bearer alice")
+	assert.Equal(t, "body:/private:Bearer alice", body)
+	assert.Equal(t, CacheMiss, status)
+	body, status = get("/private", "Authorization", "Bearer bob")
+	assert.Equal(t, "body:/private:Bearer bob", body, "not shared between credentials")
+	assert.Equal(t, CacheMiss, status)
+	body, status = get("/private", "Authorization", "Bearer alice")
+	assert.Equal(t, "body:/private:Bearer alice", body)
+	assert.Equal(t, CacheHit, status)
+	_, status = get("/private", "Authorization", "Bearer bob")
+	assert.Equal(t, CacheHit, status)
+
+	post := func(path string, header ...string) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+path, strings.NewReader("x"))
+		assert.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	post("/vary")
+	_, status = get("/vary", "Accept-Language", "fr")
+	assert.Equal(t, CacheMiss, status, "invalidated by POST")
+
+	post("/private", "Authorization", "Bearer alice")
+	_, status = get("/private", "Authorization", "Bearer alice")
+	assert.Equal(t, CacheMiss, status, "invalidated by POST")
+	_, status = get("/private", "Authorization", "Bearer bob")
+	assert.Equal(t, CacheMiss, status, "invalidated by POST with other credentials")
+}
+
+func TestLRUCacheStorage(t *testing.T) {
+	t.Parallel()
+
+	s := NewLRUCacheStorage(2, 10)
+	s.Set("a", &CachedResponse{Body: []byte("aaa")})
+	s.Set("b", &CachedResponse{Body: []byte("bbb")})
+
+	_, ok := s.Get("a")
+	assert.True(t, ok)
+
+	s.Set("c", &CachedResponse{Body: []byte("ccc")})
+	_, ok = s.Get("b")
+	assert.False(t, ok, "least recently used is evicted")
+	assert.Equal(t, 2, s.Len())
+
+	s.Set("d", &CachedResponse{Body: []byte("dddddddd")})
+	assert.Equal(t, 1, s.Len(), "evicted down to the byte limit")
+
+	s.Set("e", &CachedResponse{Body: []byte("eeeeeeeeeee")})
+	_, ok = s.Get("e")
+	assert.False(t, ok, "larger than the byte limit")
+}
+
+func TestFreshnessLifetime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := func(kv ...string) http.Header {
+		header := http.Header{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			header.Set(kv[i], kv[i+1])
+		}
+		return header
+	}
+
+	assert.Equal(t, 50*time.Second, freshnessLifetime(h("Cache-Control", "public, max-age=60", "Age", "10"), now))
+	assert.Equal(t, time.Hour, freshnessLifetime(h("Date", now.Format(http.TimeFormat), "Expires", now.Add(time.Hour).Format(http.TimeFormat)), now))
+	assert.Equal(t, time.Hour, freshnessLifetime(h("Date", now.Format(http.TimeFormat), "Last-Modified", now.Add(-10*time.Hour).Format(http.TimeFormat)), now))
+	assert.Equal(t, time.Duration(0), freshnessLifetime(h("Cache-Control", "no-cache, max-age=60"), now))
+	assert.Equal(t, time.Duration(0), freshnessLifetime(h(), now))
+}
+
+func TestTelemeterClient_SetCache(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = io.WriteString(w, `{"name":"config"}`)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.SetCache(NewLRUCacheStorage(10, 0))
+
+	for i := 0; i < 3; i++ {
+		var target struct{ Name string }
+		resp, err := c.GetJSON(context.Background(), &target, srv.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, "config", target.Name)
+		if i > 0 {
+			assert.Equal(t, CacheHit, CacheStatus(resp))
+		}
+	}
+
+	assert.Equal(t, int64(1), hits.Load())
+}
@@ -0,0 +1,392 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+// ErrNoInteraction is matched (with errors.Is) when a replaying CassetteRoundTripper has
+// no recorded interaction for a request
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// Redacted replaces redacted header values in recorded interactions
+const Redacted = "REDACTED"
+
+// CassetteMode determines whether a CassetteRoundTripper uses the network
+type CassetteMode int
+
+const (
+	// CassetteReplay serves requests only from the cassette, never using the network
+	CassetteReplay CassetteMode = iota
+	// CassetteRecord sends every request and records the interaction, replacing the cassette on Save
+	CassetteRecord
+	// CassetteReplayOrRecord serves requests from the cassette when possible, and otherwise
+	// sends them and adds the interaction to the cassette
+	CassetteReplayOrRecord
+)
+
+// Cassette is the file format of a CassetteRoundTripper
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// BodyEncodingBase64 is the BodyEncoding of recorded bodies that are not valid UTF-8 (and
+// so cannot be stored as JSON strings as-is)
+const BodyEncodingBase64 = "base64"
+
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// BodyBytes is the decoded body of the request
+func (r *RecordedRequest) BodyBytes() []byte {
+	b, _ := decodeRecordedBody(r.Body, r.BodyEncoding) // checked when the cassette is loaded
+	return b
+}
+
+type RecordedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// BodyBytes is the decoded body of the response
+func (r *RecordedResponse) BodyBytes() []byte {
+	b, _ := decodeRecordedBody(r.Body, r.BodyEncoding) // checked when the cassette is loaded
+	return b
+}
+
+// encodeRecordedBody stores b as-is if it is valid UTF-8, and otherwise as base64
+func encodeRecordedBody(b []byte) (body, encoding string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(b), BodyEncodingBase64
+}
+
+func decodeRecordedBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case BodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, errors.WithDetails(errors.New("unknown body encoding"), "body_encoding", encoding)
+	}
+}
+
+// RequestMatcher reports whether a recorded request matches req, whose body is provided
+type RequestMatcher func(req *http.Request, body []byte, recorded *RecordedRequest) bool
+
+// MatchMethodAndURL matches requests by method and full URL (including the query)
+func MatchMethodAndURL(req *http.Request, _ []byte, recorded *RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL
+}
+
+// MatchBody matches requests by their (possibly redacted) body
+func MatchBody(_ *http.Request, body []byte, recorded *RecordedRequest) bool {
+	return bytes.Equal(body, recorded.BodyBytes())
+}
+
+// MatchHeaders matches requests by the values of the named headers
+func MatchHeaders(names ...string) RequestMatcher {
+	return func(req *http.Request, _ []byte, recorded *RecordedRequest) bool {
+		for _, name := range names {
+			if strings.Join(req.Header.Values(name), ",") != strings.Join(recorded.Header.Values(name), ",") {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// MatchAll matches requests that all of the matchers match
+func MatchAll(matchers ...RequestMatcher) RequestMatcher {
+	return func(req *http.Request, body []byte, recorded *RecordedRequest) bool {
+		for _, m := range matchers {
+			if !m(req, body, recorded) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// CassetteOpt configures a CassetteRoundTripper
+type CassetteOpt func(*CassetteRoundTripper)
+
+// WithRequestMatcher sets how requests are matched to recorded interactions
+// (default MatchMethodAndURL)
+func WithRequestMatcher(m RequestMatcher) CassetteOpt {
+	return func(rt *CassetteRoundTripper) {
+		rt.matcher = m
+	}
+}
+
+// WithRedactedHeaders adds to the request and response headers whose values are
+// replaced with Redacted when recording (by default Authorization, Proxy-Authorization,
+// Cookie, and Set-Cookie)
+func WithRedactedHeaders(names ...string) CassetteOpt {
+	return func(rt *CassetteRoundTripper) {
+		for _, name := range names {
+			rt.redactHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithBodyRedactor rewrites request and response bodies before they are recorded, and
+// request bodies before they are matched
+func WithBodyRedactor(fn func(header http.Header, body []byte) []byte) CassetteOpt {
+	return func(rt *CassetteRoundTripper) {
+		rt.redactBody = fn
+	}
+}
+
+// WithReplayRepeats allows an interaction to be replayed more than once; otherwise each
+// recorded interaction is used at most once, in order
+func WithReplayRepeats() CassetteOpt {
+	return func(rt *CassetteRoundTripper) {
+		rt.repeats = true
+	}
+}
+
+// CassetteRoundTripper records HTTP interactions to a file and replays them, so that tests
+// against external APIs can run without a network
+type CassetteRoundTripper struct {
+	base          http.RoundTripper
+	path          string
+	mode          CassetteMode
+	matcher       RequestMatcher
+	redactHeaders map[string]bool
+	redactBody    func(http.Header, []byte) []byte
+	repeats       bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+var _ http.RoundTripper = (*CassetteRoundTripper)(nil)
+
+// NewCassetteRoundTripper creates a CassetteRoundTripper for the cassette at path, which
+// must exist in CassetteReplay mode. Recorded interactions are only written by Save.
+func NewCassetteRoundTripper(base http.RoundTripper, path string, mode CassetteMode, opts ...CassetteOpt) (*CassetteRoundTripper, error) {
+	rt := &CassetteRoundTripper{
+		base:    base,
+		path:    path,
+		mode:    mode,
+		matcher: MatchMethodAndURL,
+		redactHeaders: map[string]bool{
+			"Authorization":       true,
+			"Proxy-Authorization": true,
+			"Cookie":              true,
+			"Set-Cookie":          true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	if mode == CassetteRecord {
+		return rt, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err != nil && os.IsNotExist(err) && mode == CassetteReplayOrRecord:
+		return rt, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "could not read cassette", "path", path)
+	}
+
+	if err := json.Unmarshal(data, &rt.cassette); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal cassette", "path", path)
+	}
+
+	for i, in := range rt.cassette.Interactions {
+		if _, err := decodeRecordedBody(in.Request.Body, in.Request.BodyEncoding); err != nil {
+			return nil, errors.Wrap(err, "could not decode recorded request body", "path", path, "interaction", i)
+		}
+
+		if _, err := decodeRecordedBody(in.Response.Body, in.Response.BodyEncoding); err != nil {
+			return nil, errors.Wrap(err, "could not decode recorded response body", "path", path, "interaction", i)
+		}
+	}
+	rt.used = make([]bool, len(rt.cassette.Interactions))
+
+	return rt, nil
+}
+
+// Save writes the cassette, if anything could have been recorded
+func (rt *CassetteRoundTripper) Save() error {
+	if rt.mode == CassetteReplay {
+		return nil
+	}
+
+	rt.mu.Lock()
+	data, err := json.MarshalIndent(rt.cassette, "", "  ")
+	rt.mu.Unlock()
+
+	if err != nil {
+		return errors.Wrap(err, "could not marshal cassette")
+	}
+
+	if err := os.WriteFile(rt.path, append(data, '\n'), 0o600); err != nil {
+		return errors.Wrap(err, "could not write cassette", "path", rt.path)
+	}
+
+	return nil
+}
+
+func (rt *CassetteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBodyBytes(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read request body")
+	}
+
+	matchBody := rt.redact(req.Header, body)
+
+	if rt.mode != CassetteRecord {
+		if resp, ok := rt.replay(req, matchBody); ok {
+			return resp, nil
+		}
+
+		if rt.mode == CassetteReplay {
+			return nil, errors.Wrap(ErrNoInteraction, "", "method", req.Method, "url", req.URL.String())
+		}
+	}
+
+	sent := req.Clone(req.Context())
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := rt.base.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read response body")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: rt.redactHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     rt.redactHeader(resp.Header),
+		},
+	}
+	in.Request.Body, in.Request.BodyEncoding = encodeRecordedBody(matchBody)
+	in.Response.Body, in.Response.BodyEncoding = encodeRecordedBody(rt.redact(resp.Header, respBody))
+	rt.record(in)
+
+	return resp, nil
+}
+
+func (rt *CassetteRoundTripper) replay(req *http.Request, body []byte) (*http.Response, bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	found := -1
+	for i := range rt.cassette.Interactions {
+		if !rt.matcher(req, body, &rt.cassette.Interactions[i].Request) {
+			continue
+		}
+
+		if !rt.used[i] {
+			found = i
+			break
+		}
+
+		if rt.repeats && found < 0 {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return nil, false
+	}
+	rt.used[found] = true
+
+	rec := rt.cassette.Interactions[found].Response
+	respBody := rec.BodyBytes()
+
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, true
+}
+
+func (rt *CassetteRoundTripper) record(in Interaction) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.cassette.Interactions = append(rt.cassette.Interactions, in)
+	rt.used = append(rt.used, true)
+}
+
+func (rt *CassetteRoundTripper) redact(header http.Header, body []byte) []byte {
+	if rt.redactBody == nil || body == nil {
+		return body
+	}
+
+	return rt.redactBody(header, body)
+}
+
+func (rt *CassetteRoundTripper) redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if rt.redactHeaders[name] {
+			out[name] = []string{Redacted}
+		}
+	}
+
+	out.Del("Content-Length") // the replayed body sets its own length
+
+	return out
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func TestCassetteRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-Api-Secret", "shh")
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body)+" password=hunter2")
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	redactPassword := func(_ http.Header, body []byte) []byte {
+		return bytes.ReplaceAll(body, []byte("hunter2"), []byte(Redacted))
+	}
+
+	do := func(client *http.Client, method, url, body string) (string, error) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body)) //nolint:noctx // test
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret-token")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return string(b), nil
+	}
+
+	rec, err := NewCassetteRoundTripper(http.DefaultTransport, path, CassetteRecord,
+		WithRedactedHeaders("x-api-secret"),
+		WithBodyRedactor(redactPassword),
+		WithRequestMatcher(MatchAll(MatchMethodAndURL, MatchBody)),
+	)
+	assert.NoError(t, err)
+
+	recClient := &http.Client{Transport: rec}
+	got, err := do(recClient, http.MethodPost, srv.URL+"/a", "one")
+	assert.NoError(t, err)
+	assert.Equal(t, "POST /a one password=hunter2", got, "the live response is not redacted")
+
+	_, err = do(recClient, http.MethodPost, srv.URL+"/a", "two")
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Save())
+	srv.Close()
+
+	saved, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	for _, secret := range []string{"secret-token", "session=abc", "shh", "hunter2"} {
+		assert.NotContains(t, string(saved), secret)
+	}
+
+	replay, err := NewCassetteRoundTripper(nil, path, CassetteReplay,
+		WithRequestMatcher(MatchAll(MatchMethodAndURL, MatchBody)),
+	)
+	assert.NoError(t, err)
+
+	replayClient := &http.Client{Transport: replay}
+	got, err = do(replayClient, http.MethodPost, srv.URL+"/a", "two")
+	assert.NoError(t, err)
+	assert.Equal(t, "POST /a two password="+Redacted, got)
+
+	got, err = do(replayClient, http.MethodPost, srv.URL+"/a", "one")
+	assert.NoError(t, err)
+	assert.Equal(t, "POST /a one password="+Redacted, got)
+
+	_, err = do(replayClient, http.MethodPost, srv.URL+"/a", "one")
+	assert.True(t, errors.Is(err, ErrNoInteraction), "each interaction is replayed once")
+
+	_, err = NewCassetteRoundTripper(nil, filepath.Join(t.TempDir(), "missing.json"), CassetteReplay)
+	assert.Error(t, err)
+}
+
+func TestCassetteRoundTripper_binaryBodies(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe, 'o', 'k'}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append(body, binary...))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	do := func(rt http.RoundTripper) []byte {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(binary)) //nolint:noctx // test
+		assert.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return b
+	}
+
+	rec, err := NewCassetteRoundTripper(http.DefaultTransport, path, CassetteRecord)
+	assert.NoError(t, err)
+	live := do(rec)
+	assert.NoError(t, rec.Save())
+	srv.Close()
+
+	replay, err := NewCassetteRoundTripper(nil, path, CassetteReplay, WithRequestMatcher(MatchAll(MatchMethodAndURL, MatchBody)))
+	assert.NoError(t, err)
+	assert.Equal(t, live, do(replay), "non-UTF-8 bodies survive the round trip")
+	assert.Equal(t, BodyEncodingBase64, replay.cassette.Interactions[0].Response.BodyEncoding)
+}
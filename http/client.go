@@ -2,10 +2,11 @@ package http
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"net/url"
+	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-retryablehttp"
 
@@ -21,32 +22,6 @@ type (
 	ResponseHandlerFunc = retryablehttp.ResponseHandlerFunc
 )
 
-type RetryOptions struct {
-	RetryWaitMin time.Duration // Minimum time to wait
-	RetryWaitMax time.Duration // Maximum time to wait
-	RetryMax     *int          // Maximum number of retries
-}
-
-type HTTPResponseError struct { //nolint:revive // ok with stutter
-	Repsonse *http.Response
-	Body     []byte
-	Cause    error
-}
-
-func (e *HTTPResponseError) Error() string {
-	code := -1
-	if e.Repsonse != nil {
-		code = e.Repsonse.StatusCode
-	}
-
-	msg := fmt.Sprintf("HTTP Response Error: %d", code)
-	if e.Cause != nil {
-		msg += fmt.Sprintf(", cause=%v", e.Cause.Error())
-	}
-
-	return msg
-}
-
 //counterfeiter:generate . Client
 type Client interface {
 	ConfigureRetries(opts RetryOptions)
@@ -71,6 +46,28 @@ type TelemeterClient struct {
 	telemeter     *telemetry.Telemeter
 	telemeterOpts []telemetry.StartSpanOption
 	logger        logging.Logger
+	metrics       *clientMetrics
+
+	baseURL       *url.URL
+	defaultHeader http.Header
+	defaultQuery  url.Values
+
+	retriesMu sync.RWMutex
+	retries   RetryOptions
+
+	errorDecoders atomic.Pointer[[]ErrorDecoder]
+	breakers      atomic.Pointer[breakers]
+	limiters      atomic.Pointer[limiters]
+	cache         atomic.Pointer[cacheConfig]
+	hedging       atomic.Pointer[HedgeOptions]
+	compress      atomic.Pointer[CompressionOptions]
+	redirects     atomic.Pointer[RedirectOptions]
+	latency       *latencyTracker
+}
+
+type cacheConfig struct {
+	storage CacheStorage
+	opts    []CacheOpt
 }
 
 var (
@@ -79,7 +76,17 @@ var (
 )
 
 func NewTelemeterClient(logger logging.Logger, tel *telemetry.Telemeter, opts ...telemetry.StartSpanOption) *TelemeterClient {
+	return NewTelemeterClientWithTransport(logger, tel, nil, opts...)
+}
+
+// NewTelemeterClientWithTransport creates a TelemeterClient sending requests through base
+// (e.g., from NewTransport), or the go-retryablehttp default transport if base is nil
+func NewTelemeterClientWithTransport(logger logging.Logger, tel *telemetry.Telemeter, base http.RoundTripper, opts ...telemetry.StartSpanOption) *TelemeterClient {
 	client := retryablehttp.NewClient()
+	if base != nil {
+		client.HTTPClient.Transport = base
+	}
+
 	if client.HTTPClient.Transport == nil {
 		client.HTTPClient.Transport = http.DefaultTransport
 	}
@@ -87,34 +94,245 @@ func NewTelemeterClient(logger logging.Logger, tel *telemetry.Telemeter, opts ..
 	rt := NewTelemeterRoundTripper(client.HTTPClient.Transport, tel, opts...)
 	client.HTTPClient.Transport = rt
 
+	client.HTTPClient.CheckRedirect = defaultRedirectPolicy.checkRedirect
+
 	client.Logger = &HTTPLogger{
 		Logger: logger,
 	}
+	client.ErrorHandler = lastResponseErrorHandler
+
+	retryMax := client.RetryMax
 
 	return &TelemeterClient{
 		client:        client,
 		telemeter:     tel,
 		telemeterOpts: opts,
 		logger:        logger,
+		metrics:       newClientMetrics(tel),
+		latency:       newLatencyTracker(),
+		retries: RetryOptions{
+			RetryWaitMin: client.RetryWaitMin,
+			RetryWaitMax: client.RetryWaitMax,
+			RetryMax:     &retryMax,
+		},
 	}
 }
 
+// lastResponseErrorHandler hands back the final response when retries are exhausted
+// without an error (e.g., repeated 5xx responses), so that its body can be decoded into
+// an HTTPResponseError. If there was an error, the response body is closed.
+func lastResponseErrorHandler(resp *http.Response, err error, numTries int) (*http.Response, error) {
+	if err == nil && resp != nil {
+		return resp, nil
+	}
+
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	if err == nil {
+		return resp, errors.WithDetails(errors.New("giving up after retries"), "attempts", numTries)
+	}
+
+	return resp, errors.Wrap(err, "giving up after retries", "attempts", numTries)
+}
+
+// ConfigureRetries changes the default retry settings for subsequent requests. Requests
+// already in flight are unaffected, and WithRetries overrides these settings per request.
 func (c *TelemeterClient) ConfigureRetries(opts RetryOptions) {
-	if opts.RetryWaitMin > 0 {
-		c.client.RetryWaitMin = opts.RetryWaitMin
+	c.retriesMu.Lock()
+	defer c.retriesMu.Unlock()
+
+	c.retries = c.retries.merge(opts)
+}
+
+// retryClient builds a retryablehttp.Client for a single request from the client's
+// retry settings and any per-request overrides, sharing the underlying http.Client
+func (c *TelemeterClient) retryClient(cfg *requestConfig) *retryablehttp.Client {
+	c.retriesMu.RLock()
+	opts := c.retries
+	c.retriesMu.RUnlock()
+
+	if cfg != nil {
+		opts = opts.merge(cfg.retries)
+	}
+
+	shouldRetry := opts.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultRetryPredicate
+	}
+
+	var retryMax int
+	if opts.RetryMax != nil {
+		retryMax = *opts.RetryMax
+	}
+
+	return &retryablehttp.Client{
+		HTTPClient:      c.httpClient(cfg),
+		Logger:          c.client.Logger,
+		RetryWaitMin:    opts.RetryWaitMin,
+		RetryWaitMax:    opts.RetryWaitMax,
+		RetryMax:        retryMax,
+		RequestLogHook:  c.requestLogHook,
+		ResponseLogHook: c.client.ResponseLogHook,
+		CheckRetry:      shouldRetry,
+		Backoff:         opts.backoff(),
+		ErrorHandler:    c.client.ErrorHandler,
+		PrepareRetry:    c.client.PrepareRetry,
+	}
+}
+
+// SetErrorDecoders sets the decoders tried, in order, on the body of 4xx/5xx responses
+// (replacing DefaultErrorDecoders). WithErrorDecoders overrides this per request.
+func (c *TelemeterClient) SetErrorDecoders(decoders ...ErrorDecoder) {
+	c.errorDecoders.Store(&decoders)
+}
+
+// SetCircuitBreaker enables a circuit breaker per host with the provided options, replacing
+// any previous breakers. Requests to a host whose breaker is open fail with a *CircuitOpenError.
+func (c *TelemeterClient) SetCircuitBreaker(opts BreakerOptions) {
+	c.breakers.Store(newBreakers(opts, c.logger, c.telemeter))
+}
+
+// DisableCircuitBreaker removes any circuit breakers
+func (c *TelemeterClient) DisableCircuitBreaker() {
+	c.breakers.Store(nil)
+}
+
+// CircuitState is the state of the circuit breaker for host (closed if there are no breakers)
+func (c *TelemeterClient) CircuitState(host string) BreakerState {
+	b := c.breakers.Load()
+	if b == nil {
+		return BreakerClosed
+	}
+
+	return b.stateOf(host)
+}
+
+// SetLimits enables client-side rate and concurrency limits per host with the provided
+// options, replacing any previous limits
+func (c *TelemeterClient) SetLimits(opts LimitOptions) {
+	c.limiters.Store(newLimiters(opts, c.logger, c.telemeter))
+}
+
+// DisableLimits removes any client-side rate and concurrency limits
+func (c *TelemeterClient) DisableLimits() {
+	c.limiters.Store(nil)
+}
+
+// SetCache enables a CachingRoundTripper for GET requests, backed by storage, replacing
+// any previous cache. Cache hits do not count against the limits set by SetLimits.
+func (c *TelemeterClient) SetCache(storage CacheStorage, opts ...CacheOpt) {
+	c.cache.Store(&cacheConfig{storage: storage, opts: opts})
+}
+
+// DisableCache removes any cache
+func (c *TelemeterClient) DisableCache() {
+	c.cache.Store(nil)
+}
+
+// SetHedging enables hedged requests with the provided options, replacing any previous
+// settings. WithHedging and WithoutHedging override this per request.
+func (c *TelemeterClient) SetHedging(opts HedgeOptions) {
+	opts = opts.withDefaults()
+	c.hedging.Store(&opts)
+}
+
+// DisableHedging stops hedging requests (except those using WithHedging)
+func (c *TelemeterClient) DisableHedging() {
+	c.hedging.Store(nil)
+}
+
+// SetCompression enables request compression and response decompression with the provided
+// options, replacing any previous settings (see CompressingRoundTripper)
+func (c *TelemeterClient) SetCompression(opts CompressionOptions) {
+	opts = opts.withDefaults()
+	c.compress.Store(&opts)
+}
+
+// DisableCompression removes any compression settings, leaving response decompression to
+// the underlying transport
+func (c *TelemeterClient) DisableCompression() {
+	c.compress.Store(nil)
+}
+
+// SetRedirectPolicy sets how redirects are followed, replacing any previous settings.
+// WithRedirectPolicy overrides this per request.
+func (c *TelemeterClient) SetRedirectPolicy(opts RedirectOptions) {
+	c.redirects.Store(&opts)
+}
+
+func (c *TelemeterClient) responseError(httpResp *http.Response, body []byte) *HTTPResponseError {
+	decoders := DefaultErrorDecoders
+	if d := c.errorDecoders.Load(); d != nil && *d != nil {
+		decoders = *d
+	}
+
+	if cfg := requestConfigFrom(httpResp.Request.Context()); cfg != nil && cfg.errorDecoders != nil {
+		decoders = cfg.errorDecoders
+	}
+
+	return newResponseError(httpResp, body, decoders)
+}
+
+// httpClient is the underlying http.Client, with its transport wrapped by the cache,
+// hedging, limits, and compression, and its redirects checked, if any are configured
+func (c *TelemeterClient) httpClient(cfg *requestConfig) *http.Client {
+	lim, cache, hedge, compress := c.limiters.Load(), c.cache.Load(), c.hedging.Load(), c.compress.Load()
+	if cfg != nil && cfg.hedge != nil {
+		opts := cfg.hedge.withDefaults()
+		hedge = &opts
+	}
+	if cfg != nil && cfg.noHedge {
+		hedge = nil
+	}
+
+	redirects := c.redirects.Load()
+	if cfg != nil && cfg.redirects != nil {
+		redirects = cfg.redirects
+	}
+
+	if lim == nil && cache == nil && hedge == nil && compress == nil && redirects == nil {
+		return c.client.HTTPClient
+	}
+
+	hc := *c.client.HTTPClient
+	if compress != nil {
+		hc.Transport = NewCompressingRoundTripper(hc.Transport, *compress)
 	}
 
-	if opts.RetryWaitMax > 0 {
-		c.client.RetryWaitMax = opts.RetryWaitMax
+	if lim != nil {
+		hc.Transport = &limitingTransport{base: hc.Transport, limiters: lim}
 	}
 
-	if opts.RetryMax != nil && *opts.RetryMax >= 0 {
-		c.client.RetryMax = *opts.RetryMax
+	if hedge != nil {
+		hc.Transport = &hedgingTransport{base: hc.Transport, tel: c.telemeter, opts: *hedge, latency: c.latency}
+	}
+
+	if cache != nil {
+		hc.Transport = NewCachingRoundTripper(hc.Transport, cache.storage, cache.opts...)
+	}
+
+	if redirects != nil {
+		hc.CheckRedirect = redirects.checkRedirect
+	}
+
+	return &hc
+}
+
+// requestLogHook counts retries, before calling the underlying client's hook (if any)
+func (c *TelemeterClient) requestLogHook(logger retryablehttp.Logger, req *http.Request, attempt int) {
+	if attempt > 0 {
+		c.metrics.retries.Add(req.Context(), 1, telemetry.WithMetricAttributes(requestMetricAttributes(req)...))
+	}
+
+	if c.client.RequestLogHook != nil {
+		c.client.RequestLogHook(logger, req, attempt)
 	}
 }
 
 func (c *TelemeterClient) HTTPClient() *http.Client {
-	return c.client.StandardClient()
+	return c.retryClient(nil).StandardClient()
 }
 
 func (c *TelemeterClient) GetJSON(ctx context.Context, target interface{}, reqURL string, opts ...ClientOpt) (*http.Response, error) {
@@ -160,10 +378,7 @@ func (c *TelemeterClient) RequestJSON(ctx context.Context, target interface{}, m
 		if errBody != nil {
 			logger.Err("could not read response body", errBody)
 		}
-		return httpResp, &HTTPResponseError{
-			Repsonse: httpResp,
-			Body:     body,
-		}
+		return httpResp, c.responseError(httpResp, body)
 	}
 
 	return httpResp, nil
@@ -214,18 +429,23 @@ func (c *TelemeterClient) RequestBody(ctx context.Context, method, reqURL string
 		if errBodyRead != nil {
 			logger.Err("could not read response body", errBodyRead)
 		}
-		return nil, httpResp, &HTTPResponseError{
-			Repsonse: httpResp,
-			Body:     errBody,
-		}
+		return nil, httpResp, c.responseError(httpResp, errBody)
 	}
 
 	return body, httpResp, nil
 }
 
-func (c *TelemeterClient) prepareAndSendRequest(ctx context.Context, method, reqURL string, opts []ClientOpt) (httpResp *http.Response, err error) {
-	ctx, span := c.telemeter.StartSpan(ctx, "http", "prepareAndSendRequest", c.telemeterOpts...)
-	defer span.End()
+func (c *TelemeterClient) prepareAndSendRequest(ctx context.Context, method, reqURL string, opts []ClientOpt) (*http.Response, error) {
+	httpResp, span, err := c.sendRequest(ctx, method, reqURL, opts)
+	span.End()
+
+	return httpResp, err
+}
+
+// sendRequest builds and sends the request, returning the span covering it; the caller
+// is responsible for ending the span
+func (c *TelemeterClient) sendRequest(ctx context.Context, method, reqURL string, opts []ClientOpt) (httpResp *http.Response, span telemetry.Span, err error) {
+	ctx, span = c.telemeter.StartSpan(ctx, "http", "prepareAndSendRequest", c.telemeterOpts...)
 
 	defer func() {
 		if httpResp != nil {
@@ -240,9 +460,14 @@ func (c *TelemeterClient) prepareAndSendRequest(ctx context.Context, method, req
 		}
 	}()
 
-	req, err := retryablehttp.NewRequestWithContext(ctx, method, reqURL, http.NoBody)
+	reqURL, err = c.resolveURL(reqURL)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not create request")
+		return nil, span, err
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(withRequestConfig(ctx), method, reqURL, http.NoBody)
+	if err != nil {
+		return nil, span, errors.Wrap(err, "could not create request")
 	}
 
 	span.SetAttributes(
@@ -251,14 +476,30 @@ func (c *TelemeterClient) prepareAndSendRequest(ctx context.Context, method, req
 
 	for _, opt := range opts {
 		if err = opt(req); err != nil {
-			return nil, errors.Wrap(err, "could not apply request options")
+			return nil, span, errors.Wrap(err, "could not apply request options")
 		}
 	}
+	c.applyDefaults(req)
+
+	if cfg := requestConfigFrom(req.Context()); cfg != nil && cfg.route != "" {
+		span.SetAttributes(telemetry.KVString("http.route", cfg.route))
+	}
+
+	var breakerDone func(*http.Response, error)
+	if b := c.breakers.Load(); b != nil {
+		if breakerDone, err = b.allow(req.URL.Host); err != nil {
+			return nil, span, err
+		}
+	}
+
+	httpResp, err = c.retryClient(requestConfigFrom(req.Context())).Do(req)
+	if breakerDone != nil {
+		breakerDone(httpResp, err)
+	}
 
-	httpResp, err = c.client.Do(req)
 	if err != nil {
-		return httpResp, errors.Wrap(err, "could not issue request")
+		return httpResp, span, errors.Wrap(err, "could not issue request")
 	}
 
-	return httpResp, nil
+	return httpResp, span, nil
 }
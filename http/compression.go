@@ -0,0 +1,320 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// Content encodings supported by CompressingRoundTripper
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingZstd    = "zstd"
+)
+
+// ErrUnsupportedEncoding is matched (with errors.Is) when a request is to be compressed
+// with an encoding other than gzip or zstd
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// CompressionOptions configures a CompressingRoundTripper
+//
+// - RequestEncoding compresses request bodies with EncodingGzip or EncodingZstd, or not at all if empty
+// - MinSize is the smallest request body that is compressed (default 1KiB); bodies of
+// unknown length (e.g., from WithMultipartBody) or that cannot be replayed are streamed
+// as-is, rather than read into memory to be compressed
+// - AcceptEncodings are advertised in the Accept-Encoding header of requests that do not
+// already have one, and decoded from responses (default gzip, deflate, and zstd)
+type CompressionOptions struct {
+	RequestEncoding string
+	MinSize         int
+	AcceptEncodings []string
+}
+
+func (o *CompressionOptions) withDefaults() CompressionOptions {
+	opts := *o
+
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+
+	if opts.AcceptEncodings == nil {
+		opts.AcceptEncodings = []string{EncodingGzip, EncodingDeflate, EncodingZstd}
+	}
+
+	return opts
+}
+
+// CompressingRoundTripper compresses request bodies and transparently decompresses
+// responses, recording the body sizes before and after compression as span attributes.
+//
+// Responses are only decompressed if the Accept-Encoding header was added by the
+// CompressingRoundTripper, since a caller that sets it expects the encoded body.
+type CompressingRoundTripper struct {
+	base http.RoundTripper
+	opts CompressionOptions
+}
+
+var _ http.RoundTripper = (*CompressingRoundTripper)(nil)
+
+// NewCompressingRoundTripper creates a CompressingRoundTripper with the provided options
+func NewCompressingRoundTripper(base http.RoundTripper, opts CompressionOptions) *CompressingRoundTripper {
+	return &CompressingRoundTripper{
+		base: base,
+		opts: opts.withDefaults(),
+	}
+}
+
+func (rt *CompressingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := telemetry.SpanFromContext(req.Context())
+	out := req.Clone(req.Context())
+
+	if rt.opts.RequestEncoding != "" && out.Header.Get("Content-Encoding") == "" {
+		if err := rt.compressRequest(out, span); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	}
+
+	decode := false
+	if out.Header.Get("Accept-Encoding") == "" && len(rt.opts.AcceptEncodings) > 0 {
+		out.Header.Set("Accept-Encoding", strings.Join(rt.opts.AcceptEncodings, ", "))
+		decode = true
+	}
+
+	resp, err := rt.base.RoundTrip(out)
+	if err != nil || !decode || req.Method == http.MethodHead {
+		return resp, err
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || !rt.accepts(encoding) {
+		return resp, nil
+	}
+
+	body, err := newDecodingBody(resp.Body, encoding, span)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return resp, nil
+}
+
+func (rt *CompressingRoundTripper) accepts(encoding string) bool {
+	for _, e := range rt.opts.AcceptEncodings {
+		if strings.EqualFold(e, encoding) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compressRequest replaces the body of req, if it is large enough, with its compressed form
+func (rt *CompressingRoundTripper) compressRequest(req *http.Request, span telemetry.Span) error {
+	// a ContentLength of 0 with a body is an unknown length
+	if req.ContentLength < int64(rt.opts.MinSize) || req.GetBody == nil {
+		return nil
+	}
+
+	body, err := requestBodyBytes(req)
+	if err != nil {
+		return errors.Wrap(err, "could not read request body")
+	}
+
+	if len(body) < rt.opts.MinSize {
+		if body != nil {
+			setRequestBody(req, body)
+		}
+
+		return nil
+	}
+
+	compressed, err := compress(rt.opts.RequestEncoding, body)
+	if err != nil {
+		return err
+	}
+
+	setRequestBody(req, compressed)
+	req.Header.Set("Content-Encoding", rt.opts.RequestEncoding)
+
+	span.SetAttributes(
+		telemetry.KVInt("http.request.body.uncompressed_size", len(body)),
+		telemetry.KVInt("http.request.body.size", len(compressed)),
+	)
+
+	return nil
+}
+
+func setRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Del("Content-Length")
+}
+
+func compress(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var w io.WriteCloser
+	switch encoding {
+	case EncodingGzip:
+		w = gzip.NewWriter(&buf)
+	case EncodingZstd:
+		zw, err := zstd.NewWriter(&buf, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create zstd encoder")
+		}
+		w = zw
+	default:
+		return nil, errors.Wrap(ErrUnsupportedEncoding, "", "encoding", encoding)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return nil, errors.Wrap(err, "could not compress request body", "encoding", encoding)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "could not compress request body", "encoding", encoding)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// decodingBody decodes a response body, recording the encoded and decoded sizes on the
+// span when it reaches the end or is closed
+type decodingBody struct {
+	raw     io.ReadCloser
+	encoded *countingReader
+	decoder io.Reader
+	closer  func()
+	decoded int64
+	span    telemetry.Span
+	once    sync.Once
+}
+
+func newDecodingBody(raw io.ReadCloser, encoding string, span telemetry.Span) (*decodingBody, error) {
+	b := &decodingBody{
+		raw:     raw,
+		encoded: &countingReader{r: raw},
+		span:    span,
+	}
+
+	switch encoding {
+	case EncodingGzip:
+		// the gzip header is read lazily, so that an empty body is not an error until it is read
+		b.decoder = &lazyReader{open: func() (io.Reader, error) { return gzip.NewReader(b.encoded) }}
+	case EncodingDeflate:
+		b.decoder = &lazyReader{open: func() (io.Reader, error) { return newDeflateReader(b.encoded) }}
+	case EncodingZstd:
+		zr, err := zstd.NewReader(b.encoded, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create zstd decoder")
+		}
+		b.decoder = zr
+		b.closer = zr.Close
+	default:
+		return nil, errors.Wrap(ErrUnsupportedEncoding, "", "encoding", encoding)
+	}
+
+	return b, nil
+}
+
+func (b *decodingBody) Read(p []byte) (int, error) {
+	n, err := b.decoder.Read(p)
+	b.decoded += int64(n)
+
+	if errors.Is(err, io.EOF) {
+		b.record()
+	}
+
+	return n, err
+}
+
+func (b *decodingBody) Close() error {
+	b.record()
+
+	if b.closer != nil {
+		b.closer()
+	}
+
+	return b.raw.Close()
+}
+
+func (b *decodingBody) record() {
+	b.once.Do(func() {
+		b.span.SetAttributes(
+			telemetry.KVInt64("http.response.body.size", b.encoded.n),
+			telemetry.KVInt64("http.response.body.uncompressed_size", b.decoded),
+		)
+	})
+}
+
+// lazyReader opens its underlying reader on the first read
+type lazyReader struct {
+	open func() (io.Reader, error)
+	r    io.Reader
+	err  error
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.r == nil && l.err == nil {
+		l.r, l.err = l.open()
+	}
+
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	return l.r.Read(p)
+}
+
+// newDeflateReader reads a "deflate" body, which should be zlib-wrapped but is sometimes
+// raw deflate data
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+
+	return flate.NewReader(br), nil
+}
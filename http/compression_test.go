@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func encodeBody(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	var w io.WriteCloser
+	switch encoding {
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		assert.NoError(t, err)
+		w = fw
+	default:
+		compressed, err := compress(encoding, body)
+		assert.NoError(t, err)
+		return compressed
+	}
+
+	_, err := w.Write(body)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestCompressingRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(strings.Repeat("hello compression ", 200))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		switch r.Header.Get("Content-Encoding") {
+		case EncodingGzip:
+			gr, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			body = gr
+		case EncodingZstd:
+			zr, err := zstd.NewReader(r.Body)
+			assert.NoError(t, err)
+			defer zr.Close()
+			body = zr
+		}
+
+		got, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, got)
+
+		encoding := r.URL.Query().Get("encoding")
+		header := encoding
+		switch encoding {
+		case "zlib", "flate":
+			header = EncodingDeflate
+		}
+
+		w.Header().Set("Content-Encoding", header)
+		_, _ = w.Write(encodeBody(t, encoding, payload))
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		request  string
+		response string
+	}{
+		{request: EncodingGzip, response: EncodingGzip},
+		{request: EncodingZstd, response: EncodingZstd},
+		{request: EncodingGzip, response: "zlib"},
+		{request: EncodingZstd, response: "flate"},
+	} {
+		t.Run(tc.request+"/"+tc.response, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+			rt := NewCompressingRoundTripper(http.DefaultTransport, CompressionOptions{RequestEncoding: tc.request})
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"?encoding="+tc.response, bytes.NewReader(payload))
+			assert.NoError(t, err)
+
+			resp, err := rt.RoundTrip(req)
+			assert.NoError(t, err)
+
+			got, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			assert.Equal(t, payload, got)
+			assert.Empty(t, resp.Header.Get("Content-Encoding"))
+			assert.True(t, resp.Uncompressed)
+
+			span.End()
+
+			attrs := map[string]int64{}
+			for _, kv := range recorder.Ended()[0].Attributes() {
+				attrs[string(kv.Key)] = kv.Value.AsInt64()
+			}
+			assert.Equal(t, int64(len(payload)), attrs["http.request.body.uncompressed_size"])
+			assert.Less(t, attrs["http.request.body.size"], int64(len(payload)))
+			assert.Equal(t, int64(len(payload)), attrs["http.response.body.uncompressed_size"])
+			assert.Less(t, attrs["http.response.body.size"], int64(len(payload)))
+		})
+	}
+}
+
+func TestCompressingRoundTripper_passthrough(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"), "small bodies are not compressed")
+
+		w.Header().Set("Content-Encoding", EncodingGzip)
+		_, _ = w.Write(encodeBody(t, EncodingGzip, []byte("raw")))
+	}))
+	defer srv.Close()
+
+	rt := NewCompressingRoundTripper(http.DefaultTransport, CompressionOptions{RequestEncoding: EncodingGzip})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader("small"))
+	assert.NoError(t, err)
+	req.Header.Set("Accept-Encoding", EncodingGzip)
+
+	resp, err := rt.RoundTrip(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck // test
+
+	assert.Equal(t, EncodingGzip, resp.Header.Get("Content-Encoding"), "the caller asked for the encoded body")
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader("small"))
+	assert.NoError(t, err)
+
+	_, err = NewCompressingRoundTripper(http.DefaultTransport, CompressionOptions{RequestEncoding: "br", MinSize: 1}).RoundTrip(req)
+	assert.True(t, errors.Is(err, ErrUnsupportedEncoding))
+}
+
+func TestCompressingRoundTripper_streamedBody(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(strings.Repeat("streamed ", 1000))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"), "bodies of unknown length are not compressed")
+
+		got, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, got)
+	}))
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := pw.Write(payload)
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, pr)
+	assert.NoError(t, err)
+
+	resp, err := NewCompressingRoundTripper(http.DefaultTransport, CompressionOptions{RequestEncoding: EncodingGzip}).RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+}
@@ -0,0 +1,367 @@
+package http
+
+import (
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+const ContentTypeProblemJSON = "application/problem+json"
+
+// Sentinel errors that an *HTTPResponseError matches (with errors.Is) based on its status code
+var (
+	ErrClientError     = errors.New("http client error (4xx)")
+	ErrServerError     = errors.New("http server error (5xx)")
+	ErrBadRequest      = errors.New("http bad request")
+	ErrUnauthorized    = errors.New("http unauthorized")
+	ErrForbidden       = errors.New("http forbidden")
+	ErrNotFound        = errors.New("http not found")
+	ErrConflict        = errors.New("http conflict")
+	ErrTooManyRequests = errors.New("http too many requests")
+)
+
+var statusSentinels = map[error]int{
+	ErrBadRequest:      http.StatusBadRequest,
+	ErrUnauthorized:    http.StatusUnauthorized,
+	ErrForbidden:       http.StatusForbidden,
+	ErrNotFound:        http.StatusNotFound,
+	ErrConflict:        http.StatusConflict,
+	ErrTooManyRequests: http.StatusTooManyRequests,
+}
+
+// statusSentinelOrder is the order in which the statusSentinels are checked, so that an
+// error matching several of them always gets the same status
+var statusSentinelOrder = []error{
+	ErrTooManyRequests,
+	ErrUnauthorized,
+	ErrForbidden,
+	ErrNotFound,
+	ErrConflict,
+	ErrBadRequest,
+}
+
+// ErrorDetail is structured information decoded from an error response body
+type ErrorDetail interface {
+	// Summary is a short description, included in the error message
+	Summary() string
+	// Data is a list of key/value pairs, included in the error data
+	Data() []interface{}
+}
+
+// ErrorDecoder attempts to decode an error response body, reporting whether it succeeded
+type ErrorDecoder func(resp *http.Response, body []byte) (ErrorDetail, bool)
+
+// DefaultErrorDecoders are used when neither the client nor the request specify any
+var DefaultErrorDecoders = []ErrorDecoder{DecodeProblemDetails, DecodeGenericError}
+
+type HTTPResponseError struct { //nolint:revive // ok with stutter
+	Response *http.Response
+	// Deprecated: use Response
+	Repsonse *http.Response
+	Body     []byte
+	Detail   ErrorDetail
+	Cause    error
+}
+
+var _ errors.Error = (*HTTPResponseError)(nil)
+
+// newResponseError builds an *HTTPResponseError, decoding body with the first decoder that succeeds
+func newResponseError(resp *http.Response, body []byte, decoders []ErrorDecoder) *HTTPResponseError {
+	e := &HTTPResponseError{
+		Response: resp,
+		Repsonse: resp,
+		Body:     body,
+	}
+
+	if len(body) == 0 {
+		return e
+	}
+
+	for _, decode := range decoders {
+		if detail, ok := decode(resp, body); ok {
+			e.Detail = detail
+			break
+		}
+	}
+
+	return e
+}
+
+// StatusCode is the response status code, or -1 if there is no response
+func (e *HTTPResponseError) StatusCode() int {
+	switch {
+	case e.Response != nil:
+		return e.Response.StatusCode
+	case e.Repsonse != nil:
+		return e.Repsonse.StatusCode
+	default:
+		return -1
+	}
+}
+
+func (e *HTTPResponseError) Msg() string {
+	msg := fmt.Sprintf("HTTP Response Error: %d", e.StatusCode())
+	if e.Detail != nil {
+		if summary := e.Detail.Summary(); summary != "" {
+			msg += ": " + summary
+		}
+	}
+
+	return msg
+}
+
+func (e *HTTPResponseError) Error() string {
+	msg := e.Msg()
+	if e.Cause != nil {
+		msg += fmt.Sprintf(", cause=%v", e.Cause.Error())
+	}
+
+	return msg
+}
+
+func (e *HTTPResponseError) Data() []interface{} {
+	data := []interface{}{"status_code", e.StatusCode()}
+	if e.Detail != nil {
+		data = append(data, e.Detail.Data()...)
+	}
+
+	return data
+}
+
+func (e *HTTPResponseError) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches ErrClientError, ErrServerError, and the status specific sentinels
+func (e *HTTPResponseError) Is(target error) bool {
+	code := e.StatusCode()
+
+	switch target {
+	case ErrClientError:
+		return code >= 400 && code < 500
+	case ErrServerError:
+		return code >= 500 && code < 600
+	}
+
+	if want, ok := statusSentinels[target]; ok {
+		return code == want
+	}
+
+	return false
+}
+
+// IsStatus reports whether err is (or wraps) an *HTTPResponseError with the provided status code
+func IsStatus(err error, code int) bool {
+	var respErr *HTTPResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode() == code
+}
+
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrClientError)
+}
+
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServerError)
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// ProblemDetails is an RFC 7807 problem+json document
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions holds any additional members of the document
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+var _ ErrorDetail = (*ProblemDetails)(nil)
+
+// MarshalJSON includes the Extensions as top-level members
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	type plain ProblemDetails
+
+	b, err := json.MarshalToBuffer((*plain)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]json.RawMessage, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalToBuffer(members)
+}
+
+func (p *ProblemDetails) Summary() string {
+	switch {
+	case p.Title != "" && p.Detail != "":
+		return p.Title + ": " + p.Detail
+	case p.Title != "":
+		return p.Title
+	default:
+		return p.Detail
+	}
+}
+
+func (p *ProblemDetails) Data() []interface{} {
+	data := make([]interface{}, 0, 10+2*len(p.Extensions))
+	for _, kv := range [][2]string{
+		{"problem_type", p.Type},
+		{"problem_title", p.Title},
+		{"problem_detail", p.Detail},
+		{"problem_instance", p.Instance},
+	} {
+		if kv[1] != "" {
+			data = append(data, kv[0], kv[1])
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(p.Extensions)) {
+		data = append(data, "problem_"+k, string(p.Extensions[k]))
+	}
+
+	return data
+}
+
+// DecodeProblemDetails decodes application/problem+json bodies into a *ProblemDetails
+func DecodeProblemDetails(resp *http.Response, body []byte) (ErrorDetail, bool) {
+	if mediaType(resp) != ContentTypeProblemJSON {
+		return nil, false
+	}
+
+	var members map[string]json.RawMessage
+	if json.Unmarshal(body, &members) != nil {
+		return nil, false
+	}
+
+	p := &ProblemDetails{}
+	if json.Unmarshal(body, p) != nil {
+		return nil, false
+	}
+
+	for _, known := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, known)
+	}
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+
+	return p, true
+}
+
+// GenericError is the common {"error": "..."} or {"error": {"code": "...", "message": "..."}}
+// (or {"message": "...", "code": "..."}) error body shape
+type GenericError struct {
+	Code    string
+	Message string
+}
+
+var _ ErrorDetail = (*GenericError)(nil)
+
+func (g *GenericError) Summary() string {
+	if g.Code != "" && g.Message != "" {
+		return g.Code + ": " + g.Message
+	}
+
+	return g.Code + g.Message
+}
+
+func (g *GenericError) Data() []interface{} {
+	data := make([]interface{}, 0, 4)
+	if g.Code != "" {
+		data = append(data, "error_code", g.Code)
+	}
+
+	if g.Message != "" {
+		data = append(data, "error_message", g.Message)
+	}
+
+	return data
+}
+
+type genericErrorBody struct {
+	Error   json.RawMessage `json:"error"`
+	Message string          `json:"message"`
+	Code    json.RawMessage `json:"code"`
+}
+
+type genericErrorObject struct {
+	Message string          `json:"message"`
+	Code    json.RawMessage `json:"code"`
+}
+
+// DecodeGenericError decodes JSON bodies of the GenericError shapes
+func DecodeGenericError(resp *http.Response, body []byte) (ErrorDetail, bool) {
+	if mt := mediaType(resp); mt != "" && mt != ContentTypeJSON && !strings.HasSuffix(mt, "+json") {
+		return nil, false
+	}
+
+	var b genericErrorBody
+	if json.Unmarshal(body, &b) != nil {
+		return nil, false
+	}
+
+	g := &GenericError{
+		Message: b.Message,
+		Code:    rawScalar(b.Code),
+	}
+
+	if len(b.Error) > 0 {
+		var obj genericErrorObject
+		if json.Unmarshal(b.Error, &obj) == nil {
+			g.Message, g.Code = obj.Message, rawScalar(obj.Code)
+		} else if msg := rawScalar(b.Error); msg != "" {
+			g.Message = msg
+		}
+	}
+
+	if g.Message == "" && g.Code == "" {
+		return nil, false
+	}
+
+	return g, true
+}
+
+// rawScalar renders a JSON string or number as a plain string
+func rawScalar(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	return string(raw)
+}
+
+func mediaType(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	return mt
+}
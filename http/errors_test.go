@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func TestHTTPResponseError_decoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		opts        []ClientOpt
+		wantError   string
+		wantData    []interface{}
+		wantIs      []error
+	}{
+		{
+			name:        "problem details",
+			status:      http.StatusNotFound,
+			contentType: ContentTypeProblemJSON,
+			body:        `{"type":"about:blank","title":"Not Found","detail":"no user 1","zone":"b","user_id":1,"account":"a"}`,
+			wantError:   "HTTP Response Error: 404: Not Found: no user 1",
+			wantData: []interface{}{
+				"status_code", 404,
+				"problem_type", "about:blank", "problem_title", "Not Found", "problem_detail", "no user 1",
+				"problem_account", `"a"`, "problem_user_id", "1", "problem_zone", `"b"`,
+			},
+			wantIs: []error{ErrNotFound, ErrClientError},
+		},
+		{
+			name:        "generic string error",
+			status:      http.StatusConflict,
+			contentType: ContentTypeJSON,
+			body:        `{"error":"already exists"}`,
+			wantError:   "HTTP Response Error: 409: already exists",
+			wantData:    []interface{}{"status_code", 409, "error_message", "already exists"},
+			wantIs:      []error{ErrConflict, ErrClientError},
+		},
+		{
+			name:        "generic object error",
+			status:      http.StatusBadGateway,
+			contentType: ContentTypeJSON,
+			body:        `{"error":{"code":"upstream","message":"bad upstream"}}`,
+			wantError:   "HTTP Response Error: 502: upstream: bad upstream",
+			wantData:    []interface{}{"status_code", 502, "error_code", "upstream", "error_message", "bad upstream"},
+			wantIs:      []error{ErrServerError},
+		},
+		{
+			name:        "undecodable",
+			status:      http.StatusBadRequest,
+			contentType: "text/plain",
+			body:        `nope`,
+			wantError:   "HTTP Response Error: 400",
+			wantData:    []interface{}{"status_code", 400},
+			wantIs:      []error{ErrBadRequest, ErrClientError},
+		},
+		{
+			name:        "custom decoder",
+			status:      http.StatusForbidden,
+			contentType: "text/plain",
+			body:        `denied`,
+			opts: []ClientOpt{WithErrorDecoders(func(_ *http.Response, body []byte) (ErrorDetail, bool) {
+				return &GenericError{Message: strings.ToUpper(string(body))}, true
+			})},
+			wantError: "HTTP Response Error: 403: DENIED",
+			wantData:  []interface{}{"status_code", 403, "error_message", "DENIED"},
+			wantIs:    []error{ErrForbidden},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, _, err := newTestClient(t).GetBody(context.Background(), srv.URL, tt.opts...)
+
+			var respErr *HTTPResponseError
+			assert.ErrorAs(t, err, &respErr)
+			assert.Equal(t, tt.wantError, respErr.Error())
+			assert.Equal(t, tt.wantData, respErr.Data())
+			assert.Equal(t, tt.status, respErr.Repsonse.StatusCode)
+
+			for _, want := range tt.wantIs {
+				assert.True(t, errors.Is(err, want), "should be %v", want)
+			}
+			assert.False(t, errors.Is(err, ErrUnauthorized))
+		})
+	}
+}
+
+func TestTelemeterClient_SetErrorDecoders(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	upper := func(_ *http.Response, body []byte) (ErrorDetail, bool) {
+		return &GenericError{Message: strings.ToUpper(string(body))}, true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			c.SetErrorDecoders(upper)
+		}
+	}()
+
+	for range 10 {
+		_, _, err := c.GetBody(context.Background(), srv.URL)
+		assert.Error(t, err)
+	}
+	<-done
+
+	_, _, err := c.GetBody(context.Background(), srv.URL)
+	assert.Contains(t, err.Error(), "SHORT AND STOUT")
+
+	c.SetErrorDecoders()
+	_, _, err = c.GetBody(context.Background(), srv.URL)
+	assert.NotContains(t, err.Error(), "SHORT AND STOUT", "no decoders restores the defaults")
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+const ContentTypeForm = "application/x-www-form-urlencoded"
+
+// WithFormBody uses the url-encoded values as the request body, setting the Content-Type
+// header. The body is replayed on retries.
+func WithFormBody(values url.Values) ClientOpt {
+	return func(req *Request) error {
+		if err := req.SetBody([]byte(values.Encode())); err != nil {
+			return errors.Wrap(err, "could not set request body")
+		}
+
+		req.Header.Set("Content-Type", ContentTypeForm)
+
+		return nil
+	}
+}
+
+// MultipartFile is a file part of a multipart/form-data body, read from Path if it is set
+// and from Reader otherwise. FileName defaults to the base name of Path, and ContentType
+// to application/octet-stream.
+//
+// To replay the body on retries, the file at Path is re-opened, and Reader is rewound if it
+// is an io.Seeker (and otherwise read into memory).
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Path        string
+	Reader      io.Reader
+}
+
+// opener returns a function that opens the contents of f for each attempt
+func (f *MultipartFile) opener() (func() (io.ReadCloser, error), error) {
+	if f.Path != "" {
+		path := f.Path
+		return func() (io.ReadCloser, error) {
+			return os.Open(path) //nolint:gosec // the caller chooses the file to upload
+		}, nil
+	}
+
+	if f.Reader == nil {
+		return nil, errors.WithDetails(errors.New("multipart file has neither a path nor a reader"), "field", f.FieldName)
+	}
+
+	if rs, ok := f.Reader.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not find the reader offset", "field", f.FieldName)
+		}
+
+		return func() (io.ReadCloser, error) {
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return nil, errors.Wrap(err, "could not rewind the reader", "field", f.FieldName)
+			}
+
+			return io.NopCloser(rs), nil
+		}, nil
+	}
+
+	data, err := io.ReadAll(f.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read multipart file", "field", f.FieldName)
+	}
+
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, nil
+}
+
+type multipartFilePart struct {
+	header textproto.MIMEHeader
+	open   func() (io.ReadCloser, error)
+}
+
+// WithMultipartBody uses a multipart/form-data body with the fields and files as the
+// request body, setting the Content-Type header (with the boundary). Fields are written
+// in sorted order, before the files. The body is streamed, and rebuilt for each retry.
+func WithMultipartBody(fields url.Values, files ...MultipartFile) ClientOpt {
+	return func(req *Request) error {
+		parts := make([]multipartFilePart, 0, len(files))
+		for i := range files {
+			open, err := files[i].opener()
+			if err != nil {
+				return err
+			}
+
+			parts = append(parts, multipartFilePart{header: files[i].partHeader(), open: open})
+		}
+
+		boundary := multipart.NewWriter(io.Discard).Boundary()
+
+		body := func() (io.Reader, error) {
+			pr, pw := io.Pipe()
+
+			go func() {
+				pw.CloseWithError(writeMultipart(pw, boundary, fields, parts))
+			}()
+
+			return pr, nil
+		}
+
+		if err := req.SetBody(body); err != nil {
+			return errors.Wrap(err, "could not set request body")
+		}
+
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+		return nil
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (f *MultipartFile) partHeader() textproto.MIMEHeader {
+	fileName := f.FileName
+	if fileName == "" && f.Path != "" {
+		fileName = filepath.Base(f.Path)
+	}
+
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="`+quoteEscaper.Replace(f.FieldName)+`"; filename="`+quoteEscaper.Replace(fileName)+`"`)
+	h.Set("Content-Type", contentType)
+
+	return h
+}
+
+func writeMultipart(w io.Writer, boundary string, fields url.Values, parts []multipartFilePart) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return errors.Wrap(err, "could not set multipart boundary")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		for _, v := range fields[name] {
+			if err := mw.WriteField(name, v); err != nil {
+				return errors.Wrap(err, "could not write multipart field", "field", name)
+			}
+		}
+	}
+
+	for _, part := range parts {
+		if err := writeMultipartFile(mw, part); err != nil {
+			return err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return errors.Wrap(err, "could not finish multipart body")
+	}
+
+	return nil
+}
+
+func writeMultipartFile(mw *multipart.Writer, part multipartFilePart) error {
+	pw, err := mw.CreatePart(part.header)
+	if err != nil {
+		return errors.Wrap(err, "could not create multipart file part")
+	}
+
+	r, err := part.open()
+	if err != nil {
+		return errors.Wrap(err, "could not open multipart file")
+	}
+	defer r.Close() //nolint:errcheck // read-only
+
+	if _, err := io.Copy(pw, r); err != nil {
+		return errors.Wrap(err, "could not write multipart file")
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFormBody(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ContentTypeForm, r.Header.Get("Content-Type"))
+		assert.NoError(t, r.ParseForm())
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = io.WriteString(w, r.PostForm.Get("name")+" "+strings.Join(r.PostForm["tag"], ","))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+
+	body, _, err := c.PostBody(context.Background(), srv.URL, WithRetryMax(1), WithFormBody(url.Values{
+		"name": {"a b&c"},
+		"tag":  {"x", "y"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a b&c x,y", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestWithMultipartBody(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600))
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal(t, "hello", r.MultipartForm.Value["title"][0])
+
+		for _, field := range []string{"report", "notes", "blob"} {
+			fhs := r.MultipartForm.File[field]
+			if !assert.Len(t, fhs, 1, field) {
+				continue
+			}
+
+			f, err := fhs[0].Open()
+			assert.NoError(t, err)
+			data, err := io.ReadAll(f)
+			assert.NoError(t, err)
+			_ = f.Close()
+
+			_, _ = io.WriteString(w, fhs[0].Filename+"|"+fhs[0].Header.Get("Content-Type")+"|"+string(data)+"\n")
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+
+	body, _, err := c.PostBody(context.Background(), srv.URL, WithRetryMax(1), WithMultipartBody(
+		url.Values{"title": {"hello"}},
+		MultipartFile{FieldName: "report", Path: path, ContentType: "text/csv"},
+		MultipartFile{FieldName: "notes", FileName: "notes.txt", Reader: strings.NewReader("seekable")},
+		MultipartFile{FieldName: "blob", FileName: "blob.bin", Reader: io.LimitReader(strings.NewReader("buffered!"), 8)},
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load(), "the body is replayed")
+	assert.Equal(t, "report.csv|text/csv|a,b\n1,2\n\nnotes.txt|application/octet-stream|seekable\nblob.bin|application/octet-stream|buffered\n", string(body))
+
+	_, _, err = c.PostBody(context.Background(), srv.URL, WithMultipartBody(nil, MultipartFile{FieldName: "missing"}))
+	assert.Error(t, err)
+}
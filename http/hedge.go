@@ -0,0 +1,301 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// HedgeOptions configures hedged requests: if a request has not received a response
+// after a delay, a duplicate is sent, and the first successful (non-5xx) response wins.
+// The others are canceled.
+//
+// - Delay is how long to wait before each hedge (default DefaultHedgeDelay)
+// - Percentile, if set (e.g., 0.95), replaces Delay with that percentile of the recent
+// response times from the host, once there are at least MinSamples of them (default 20);
+// until then, Delay is used
+// - MaxHedges is the number of duplicates that may be sent (default 1)
+// - Methods are the methods that may be hedged (default GET, HEAD, and OPTIONS); requests
+// with a body that cannot be replayed are never hedged
+type HedgeOptions struct {
+	Delay      time.Duration
+	Percentile float64
+	MinSamples int
+	MaxHedges  int
+	Methods    []string
+}
+
+// DefaultHedgeDelay is the default delay before a hedge, so that hedging does not
+// duplicate every request
+const DefaultHedgeDelay = 100 * time.Millisecond
+
+func (o *HedgeOptions) withDefaults() HedgeOptions {
+	opts := *o
+
+	if opts.Delay <= 0 {
+		opts.Delay = DefaultHedgeDelay
+	}
+
+	if opts.MinSamples <= 0 {
+		opts.MinSamples = 20
+	}
+
+	if opts.MaxHedges <= 0 {
+		opts.MaxHedges = 1
+	}
+
+	if opts.Methods == nil {
+		opts.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+	}
+
+	return opts
+}
+
+// WithHedging overrides the client's hedging settings for this request
+func WithHedging(opts HedgeOptions) ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.hedge = &opts
+			cfg.noHedge = false
+		})
+
+		return nil
+	}
+}
+
+// WithoutHedging disables hedging for this request
+func WithoutHedging() ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.hedge = nil
+			cfg.noHedge = true
+		})
+
+		return nil
+	}
+}
+
+const latencySamples = 128
+
+// latencyTracker keeps the recent response times per host
+type latencyTracker struct {
+	mu     sync.Mutex
+	byHost map[string]*latencyRing
+}
+
+type latencyRing struct {
+	samples [latencySamples]time.Duration
+	next    int
+	count   int
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{byHost: map[string]*latencyRing{}}
+}
+
+func (t *latencyTracker) observe(host string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ring, ok := t.byHost[host]
+	if !ok {
+		ring = &latencyRing{}
+		t.byHost[host] = ring
+	}
+
+	ring.samples[ring.next] = d
+	ring.next = (ring.next + 1) % latencySamples
+	ring.count = min(ring.count+1, latencySamples)
+}
+
+// percentile reports the p-th percentile of the samples for host, if there are at least minSamples
+func (t *latencyTracker) percentile(host string, p float64, minSamples int) (time.Duration, bool) {
+	t.mu.Lock()
+	ring, ok := t.byHost[host]
+	if !ok || ring.count < minSamples {
+		t.mu.Unlock()
+		return 0, false
+	}
+	samples := slices.Clone(ring.samples[:ring.count])
+	t.mu.Unlock()
+
+	slices.Sort(samples)
+	idx := min(int(p*float64(len(samples))), len(samples)-1)
+
+	return samples[max(idx, 0)], true
+}
+
+// hedgingTransport sends hedged requests through base
+type hedgingTransport struct {
+	base    http.RoundTripper
+	tel     *telemetry.Telemeter
+	opts    HedgeOptions
+	latency *latencyTracker
+}
+
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+	cancel  context.CancelFunc
+}
+
+func (t *hedgingTransport) delay(host string) time.Duration {
+	if t.opts.Percentile > 0 {
+		if d, ok := t.latency.percentile(host, t.opts.Percentile, t.opts.MinSamples); ok {
+			return d
+		}
+	}
+
+	return t.opts.Delay
+}
+
+func (t *hedgingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !replayable || !slices.Contains(t.opts.Methods, req.Method) {
+		return t.base.RoundTrip(req)
+	}
+
+	host := req.URL.Host
+	parent := telemetry.SpanFromContext(req.Context())
+	results := make(chan hedgeResult, t.opts.MaxHedges+1)
+	cancels := make([]context.CancelFunc, 0, t.opts.MaxHedges+1)
+	start := time.Now()
+
+	launch := func(attempt int) {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		ctx, span := t.tel.StartSpan(ctx, "http", "HedgeAttempt", telemetry.WithAttributes(
+			telemetry.KVInt("http.hedge.attempt", attempt),
+		))
+
+		attemptReq := req.Clone(ctx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				span.End()
+				results <- hedgeResult{attempt: attempt, err: err, cancel: cancel}
+				return
+			}
+			attemptReq.Body = body
+		}
+
+		if attempt > 0 {
+			parent.AddEvent("http.hedge", telemetry.WithAttributes(
+				telemetry.KVInt("http.hedge.attempt", attempt),
+				telemetry.KVFloat64("http.hedge.elapsed_seconds", time.Since(start).Seconds()),
+			))
+		}
+
+		go func() {
+			defer span.End()
+
+			resp, err := t.base.RoundTrip(attemptReq)
+			if err != nil {
+				span.SetStatus(telemetry.CodeError, err.Error())
+			}
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err, cancel: cancel}
+		}()
+	}
+
+	launch(0)
+	launched, outstanding := 1, 1
+
+	timer := time.NewTimer(t.delay(host))
+	defer timer.Stop()
+
+	var failed *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if launched <= t.opts.MaxHedges {
+				launch(launched)
+				launched++
+				outstanding++
+				timer.Reset(t.delay(host))
+			}
+
+		case res := <-results:
+			outstanding--
+
+			if res.err == nil && res.resp.StatusCode < 500 {
+				t.latency.observe(host, time.Since(start))
+				parent.SetAttributes(
+					telemetry.KVInt("http.hedge.count", launched-1),
+					telemetry.KVInt("http.hedge.winner", res.attempt),
+				)
+
+				for i, cancel := range cancels {
+					if i != res.attempt {
+						cancel()
+					}
+				}
+
+				discardHedges(results, outstanding, failed)
+				res.resp.Body = &cancelingBody{ReadCloser: res.resp.Body, cancel: res.cancel}
+
+				return res.resp, nil
+			}
+
+			if failed == nil {
+				failed = &res
+			} else {
+				discardHedges(nil, 0, &res)
+			}
+
+			if outstanding == 0 {
+				parent.SetAttributes(telemetry.KVInt("http.hedge.count", launched-1))
+
+				if failed.resp != nil && failed.resp.Body != nil {
+					failed.resp.Body = &cancelingBody{ReadCloser: failed.resp.Body, cancel: failed.cancel}
+				} else {
+					failed.cancel()
+				}
+
+				return failed.resp, failed.err
+			}
+		}
+	}
+}
+
+// discardHedges cleans up the losing attempts: the failed result (if any) and the
+// outstanding attempts, as they finish
+func discardHedges(results <-chan hedgeResult, outstanding int, failed *hedgeResult) {
+	closeResult := func(res hedgeResult) {
+		res.cancel()
+		if res.resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.resp.Body, 4096))
+			_ = res.resp.Body.Close()
+		}
+	}
+
+	if failed != nil {
+		closeResult(*failed)
+	}
+
+	if outstanding == 0 {
+		return
+	}
+
+	go func() {
+		for range outstanding {
+			closeResult(<-results)
+		}
+	}()
+}
+
+// cancelingBody cancels the context of a winning attempt once its body is closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
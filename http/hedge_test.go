@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelemeterClient_hedging(t *testing.T) {
+	t.Parallel()
+
+	var calls, canceled atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				canceled.Add(1)
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.SetHedging(HedgeOptions{Delay: 20 * time.Millisecond})
+
+	start := time.Now()
+	body, resp, err := c.GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Less(t, time.Since(start), 2*time.Second, "the hedge wins")
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Eventually(t, func() bool { return canceled.Load() == 1 }, time.Second, 5*time.Millisecond, "the slow attempt is canceled")
+
+	calls.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err = c.PostBody(ctx, srv.URL, WithHedging(HedgeOptions{Delay: time.Millisecond}))
+	assert.Error(t, err, "POST is not hedged by default")
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func TestLatencyTracker_percentile(t *testing.T) {
+	t.Parallel()
+
+	lt := newLatencyTracker()
+
+	_, ok := lt.percentile("a", 0.9, 10)
+	assert.False(t, ok)
+
+	for i := 1; i <= 200; i++ {
+		lt.observe("a", time.Duration(i)*time.Millisecond)
+	}
+
+	p, ok := lt.percentile("a", 0.9, 10)
+	assert.True(t, ok)
+	assert.Equal(t, 188*time.Millisecond, p, "only the most recent samples are kept")
+}
+
+func TestHedgingTransport_delay(t *testing.T) {
+	t.Parallel()
+
+	opts := HedgeOptions{Percentile: 0.5, MinSamples: 2}
+	ht := &hedgingTransport{opts: opts.withDefaults(), latency: newLatencyTracker()}
+	assert.Equal(t, DefaultHedgeDelay, ht.delay("a"), "Delay is used until there are MinSamples")
+
+	ht.latency.observe("a", 300*time.Millisecond)
+	ht.latency.observe("a", 300*time.Millisecond)
+	assert.Equal(t, 300*time.Millisecond, ht.delay("a"))
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+func newTestTelemeter(t *testing.T) *telemetry.Telemeter {
+	t.Helper()
+
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(io.Discard))
+	assert.NoError(t, err, "failed to construct stdout exporter")
+
+	return telemetry.NewTelemeter("test", "v0", "test_instance", exp, nil, 1.0)
+}
+
+func newTestClient(t *testing.T) *TelemeterClient {
+	t.Helper()
+
+	c := NewTelemeterClient(logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+
+	zero := 0
+	c.ConfigureRetries(RetryOptions{RetryWaitMin: 1, RetryWaitMax: 1, RetryMax: &zero})
+
+	return c
+}
+
+// recordingMeterProvider is a MeterProvider whose counters, up/down counters, and
+// histograms record the values they receive
+type recordingMeterProvider struct {
+	noop.MeterProvider
+
+	mu     sync.Mutex
+	values map[string][]recordedValue
+}
+
+type recordedValue struct {
+	value float64
+	attrs attribute.Set
+}
+
+func newRecordingMeterProvider() *recordingMeterProvider {
+	return &recordingMeterProvider{values: map[string][]recordedValue{}}
+}
+
+func (p *recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return recordingMeter{p: p}
+}
+
+func (p *recordingMeterProvider) record(name string, v float64, attrs attribute.Set) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.values[name] = append(p.values[name], recordedValue{value: v, attrs: attrs})
+}
+
+// recorded returns the values recorded for the instrument name
+func (p *recordingMeterProvider) recorded(name string) []recordedValue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]recordedValue(nil), p.values[name]...)
+}
+
+type recordingMeter struct {
+	noop.Meter
+	p *recordingMeterProvider
+}
+
+func (m recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingInt64{p: m.p, name: name}, nil
+}
+
+func (m recordingMeter) Int64UpDownCounter(name string, _ ...metric.Int64UpDownCounterOption) (metric.Int64UpDownCounter, error) {
+	return recordingInt64{p: m.p, name: name}, nil
+}
+
+func (m recordingMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return recordingFloat64{p: m.p, name: name}, nil
+}
+
+type recordingInt64 struct {
+	noop.Int64Counter
+	noop.Int64UpDownCounter
+	p    *recordingMeterProvider
+	name string
+}
+
+func (r recordingInt64) Add(_ context.Context, v int64, opts ...metric.AddOption) {
+	r.p.record(r.name, float64(v), metric.NewAddConfig(opts).Attributes())
+}
+
+type recordingFloat64 struct {
+	noop.Float64Histogram
+	p    *recordingMeterProvider
+	name string
+}
+
+func (r recordingFloat64) Record(_ context.Context, v float64, opts ...metric.RecordOption) {
+	r.p.record(r.name, v, metric.NewRecordConfig(opts).Attributes())
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+// Headers set by HMACRoundTripper and checked by VerifyHMAC
+const (
+	HMACTimestampHeader     = "X-Signature-Timestamp"
+	HMACContentSHA256Header = "X-Content-SHA256"
+	HMACScheme              = "HMAC-SHA256"
+)
+
+// Errors returned by VerifyHMAC
+var (
+	ErrSignatureMissing = errors.New("request signature missing")
+	ErrSignatureInvalid = errors.New("request signature invalid")
+	ErrSignatureExpired = errors.New("request signature timestamp outside the allowed skew")
+)
+
+// HMACRoundTripper signs every request (including each retry) with HMAC-SHA256. The
+// signature covers the method, the request URI, the timestamp, and the SHA-256 of the body,
+// and is sent as
+//
+//	Authorization: HMAC-SHA256 keyId="<keyID>",signature="<hex>"
+//
+// along with HMACTimestampHeader (unix seconds) and HMACContentSHA256Header (hex).
+// Redirects to another origin are not signed (see IsCrossOriginRedirect).
+type HMACRoundTripper struct {
+	base   http.RoundTripper
+	keyID  string
+	secret []byte
+	now    func() time.Time
+}
+
+var _ http.RoundTripper = (*HMACRoundTripper)(nil)
+
+func NewHMACRoundTripper(base http.RoundTripper, keyID string, secret []byte) *HMACRoundTripper {
+	return &HMACRoundTripper{
+		base:   base,
+		keyID:  keyID,
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+func (rt *HMACRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if IsCrossOriginRedirect(req) {
+		return rt.base.RoundTrip(req)
+	}
+
+	body, err := requestBodyBytes(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read request body for signing")
+	}
+
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	contentHash := sha256.Sum256(body)
+	contentHex := hex.EncodeToString(contentHash[:])
+	timestamp := strconv.FormatInt(rt.now().Unix(), 10)
+
+	signed.Header.Set(HMACTimestampHeader, timestamp)
+	signed.Header.Set(HMACContentSHA256Header, contentHex)
+	signed.Header.Set("Authorization", HMACScheme+` keyId="`+rt.keyID+`",signature="`+
+		hmacSignature(rt.secret, signed.Method, signed.URL.RequestURI(), timestamp, contentHex)+`"`)
+
+	return rt.base.RoundTrip(signed)
+}
+
+// requestBodyBytes reads and closes the request body
+func requestBodyBytes(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	return body, err
+}
+
+func hmacSignature(secret []byte, method, requestURI, timestamp, contentHex string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = io.WriteString(mac, strings.Join([]string{method, requestURI, timestamp, contentHex}, "\n"))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC checks the signature that an HMACRoundTripper added to req, looking up the
+// secret by key ID and rejecting timestamps more than maxSkew from now. The body is read
+// and replaced so that it can still be read by the caller. It returns the key ID.
+func VerifyHMAC(req *http.Request, secretFor func(keyID string) ([]byte, bool), maxSkew time.Duration) (string, error) {
+	scheme, params, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || scheme != HMACScheme {
+		return "", ErrSignatureMissing
+	}
+
+	var keyID, signature string
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		value = strings.Trim(value, `"`)
+
+		switch name {
+		case "keyId":
+			keyID = value
+		case "signature":
+			signature = value
+		}
+	}
+
+	secret, ok := secretFor(keyID)
+	if !ok || signature == "" {
+		return keyID, errors.Wrap(ErrSignatureInvalid, "unknown key", "key_id", keyID)
+	}
+
+	timestamp := req.Header.Get(HMACTimestampHeader)
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return keyID, errors.Wrap(ErrSignatureInvalid, "bad timestamp", "key_id", keyID)
+	}
+
+	if skew := time.Since(time.Unix(secs, 0)); skew > maxSkew || skew < -maxSkew {
+		return keyID, errors.Wrap(ErrSignatureExpired, "", "key_id", keyID, "skew", skew)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return keyID, errors.Wrap(err, "could not read request body")
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	contentHash := sha256.Sum256(body)
+	contentHex := hex.EncodeToString(contentHash[:])
+
+	want := hmacSignature(secret, req.Method, req.URL.RequestURI(), timestamp, contentHex)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return keyID, errors.Wrap(ErrSignatureInvalid, "signature mismatch", "key_id", keyID)
+	}
+
+	return keyID, nil
+}
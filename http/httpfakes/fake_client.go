@@ -6,8 +6,6 @@ import (
 	httpa "net/http"
 	"sync"
 
-	retryablehttp "github.com/hashicorp/go-retryablehttp"
-
 	"github.com/gsmcwhirter/go-util/v12/http"
 )
 
@@ -17,12 +15,12 @@ type FakeClient struct {
 	configureRetriesArgsForCall []struct {
 		arg1 http.RetryOptions
 	}
-	DeleteBodyStub        func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	DeleteBodyStub        func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	deleteBodyMutex       sync.RWMutex
 	deleteBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}
 	deleteBodyReturns struct {
 		result1 []byte
@@ -34,13 +32,13 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	DeleteJSONStub        func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	DeleteJSONStub        func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)
 	deleteJSONMutex       sync.RWMutex
 	deleteJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	deleteJSONReturns struct {
 		result1 *httpa.Response
@@ -50,12 +48,12 @@ type FakeClient struct {
 		result1 *httpa.Response
 		result2 error
 	}
-	GetBodyStub        func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	GetBodyStub        func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	getBodyMutex       sync.RWMutex
 	getBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}
 	getBodyReturns struct {
 		result1 []byte
@@ -67,13 +65,13 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	GetJSONStub        func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	GetJSONStub        func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)
 	getJSONMutex       sync.RWMutex
 	getJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	getJSONReturns struct {
 		result1 *httpa.Response
@@ -83,12 +81,12 @@ type FakeClient struct {
 		result1 *httpa.Response
 		result2 error
 	}
-	PatchBodyStub        func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	PatchBodyStub        func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	patchBodyMutex       sync.RWMutex
 	patchBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}
 	patchBodyReturns struct {
 		result1 []byte
@@ -100,13 +98,13 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	PatchJSONStub        func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	PatchJSONStub        func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)
 	patchJSONMutex       sync.RWMutex
 	patchJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	patchJSONReturns struct {
 		result1 *httpa.Response
@@ -116,12 +114,12 @@ type FakeClient struct {
 		result1 *httpa.Response
 		result2 error
 	}
-	PostBodyStub        func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	PostBodyStub        func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	postBodyMutex       sync.RWMutex
 	postBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}
 	postBodyReturns struct {
 		result1 []byte
@@ -133,13 +131,13 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	PostJSONStub        func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	PostJSONStub        func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)
 	postJSONMutex       sync.RWMutex
 	postJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	postJSONReturns struct {
 		result1 *httpa.Response
@@ -149,12 +147,12 @@ type FakeClient struct {
 		result1 *httpa.Response
 		result2 error
 	}
-	PutBodyStub        func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	PutBodyStub        func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	putBodyMutex       sync.RWMutex
 	putBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}
 	putBodyReturns struct {
 		result1 []byte
@@ -166,13 +164,13 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	PutJSONStub        func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	PutJSONStub        func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)
 	putJSONMutex       sync.RWMutex
 	putJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	putJSONReturns struct {
 		result1 *httpa.Response
@@ -182,13 +180,13 @@ type FakeClient struct {
 		result1 *httpa.Response
 		result2 error
 	}
-	RequestBodyStub        func(context.Context, string, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)
+	RequestBodyStub        func(context.Context, string, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)
 	requestBodyMutex       sync.RWMutex
 	requestBodyArgsForCall []struct {
 		arg1 context.Context
 		arg2 string
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}
 	requestBodyReturns struct {
 		result1 []byte
@@ -200,14 +198,14 @@ type FakeClient struct {
 		result2 *httpa.Response
 		result3 error
 	}
-	RequestJSONStub        func(context.Context, interface{}, string, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)
+	RequestJSONStub        func(context.Context, interface{}, string, string, ...http.ClientOpt) (*httpa.Response, error)
 	requestJSONMutex       sync.RWMutex
 	requestJSONArgsForCall []struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
 		arg4 string
-		arg5 []func(req *retryablehttp.Request) error
+		arg5 []http.ClientOpt
 	}
 	requestJSONReturns struct {
 		result1 *httpa.Response
@@ -253,13 +251,13 @@ func (fake *FakeClient) ConfigureRetriesArgsForCall(i int) http.RetryOptions {
 	return argsForCall.arg1
 }
 
-func (fake *FakeClient) DeleteBody(arg1 context.Context, arg2 string, arg3 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) DeleteBody(arg1 context.Context, arg2 string, arg3 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.deleteBodyMutex.Lock()
 	ret, specificReturn := fake.deleteBodyReturnsOnCall[len(fake.deleteBodyArgsForCall)]
 	fake.deleteBodyArgsForCall = append(fake.deleteBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}{arg1, arg2, arg3})
 	stub := fake.DeleteBodyStub
 	fakeReturns := fake.deleteBodyReturns
@@ -280,13 +278,13 @@ func (fake *FakeClient) DeleteBodyCallCount() int {
 	return len(fake.deleteBodyArgsForCall)
 }
 
-func (fake *FakeClient) DeleteBodyCalls(stub func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) DeleteBodyCalls(stub func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.deleteBodyMutex.Lock()
 	defer fake.deleteBodyMutex.Unlock()
 	fake.DeleteBodyStub = stub
 }
 
-func (fake *FakeClient) DeleteBodyArgsForCall(i int) (context.Context, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) DeleteBodyArgsForCall(i int) (context.Context, string, []http.ClientOpt) {
 	fake.deleteBodyMutex.RLock()
 	defer fake.deleteBodyMutex.RUnlock()
 	argsForCall := fake.deleteBodyArgsForCall[i]
@@ -322,14 +320,14 @@ func (fake *FakeClient) DeleteBodyReturnsOnCall(i int, result1 []byte, result2 *
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) DeleteJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) DeleteJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.deleteJSONMutex.Lock()
 	ret, specificReturn := fake.deleteJSONReturnsOnCall[len(fake.deleteJSONArgsForCall)]
 	fake.deleteJSONArgsForCall = append(fake.deleteJSONArgsForCall, struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.DeleteJSONStub
 	fakeReturns := fake.deleteJSONReturns
@@ -350,13 +348,13 @@ func (fake *FakeClient) DeleteJSONCallCount() int {
 	return len(fake.deleteJSONArgsForCall)
 }
 
-func (fake *FakeClient) DeleteJSONCalls(stub func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) DeleteJSONCalls(stub func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.deleteJSONMutex.Lock()
 	defer fake.deleteJSONMutex.Unlock()
 	fake.DeleteJSONStub = stub
 }
 
-func (fake *FakeClient) DeleteJSONArgsForCall(i int) (context.Context, interface{}, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) DeleteJSONArgsForCall(i int) (context.Context, interface{}, string, []http.ClientOpt) {
 	fake.deleteJSONMutex.RLock()
 	defer fake.deleteJSONMutex.RUnlock()
 	argsForCall := fake.deleteJSONArgsForCall[i]
@@ -389,13 +387,13 @@ func (fake *FakeClient) DeleteJSONReturnsOnCall(i int, result1 *httpa.Response,
 	}{result1, result2}
 }
 
-func (fake *FakeClient) GetBody(arg1 context.Context, arg2 string, arg3 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) GetBody(arg1 context.Context, arg2 string, arg3 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.getBodyMutex.Lock()
 	ret, specificReturn := fake.getBodyReturnsOnCall[len(fake.getBodyArgsForCall)]
 	fake.getBodyArgsForCall = append(fake.getBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}{arg1, arg2, arg3})
 	stub := fake.GetBodyStub
 	fakeReturns := fake.getBodyReturns
@@ -416,13 +414,13 @@ func (fake *FakeClient) GetBodyCallCount() int {
 	return len(fake.getBodyArgsForCall)
 }
 
-func (fake *FakeClient) GetBodyCalls(stub func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) GetBodyCalls(stub func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.getBodyMutex.Lock()
 	defer fake.getBodyMutex.Unlock()
 	fake.GetBodyStub = stub
 }
 
-func (fake *FakeClient) GetBodyArgsForCall(i int) (context.Context, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) GetBodyArgsForCall(i int) (context.Context, string, []http.ClientOpt) {
 	fake.getBodyMutex.RLock()
 	defer fake.getBodyMutex.RUnlock()
 	argsForCall := fake.getBodyArgsForCall[i]
@@ -458,14 +456,14 @@ func (fake *FakeClient) GetBodyReturnsOnCall(i int, result1 []byte, result2 *htt
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) GetJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) GetJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.getJSONMutex.Lock()
 	ret, specificReturn := fake.getJSONReturnsOnCall[len(fake.getJSONArgsForCall)]
 	fake.getJSONArgsForCall = append(fake.getJSONArgsForCall, struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.GetJSONStub
 	fakeReturns := fake.getJSONReturns
@@ -486,13 +484,13 @@ func (fake *FakeClient) GetJSONCallCount() int {
 	return len(fake.getJSONArgsForCall)
 }
 
-func (fake *FakeClient) GetJSONCalls(stub func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) GetJSONCalls(stub func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.getJSONMutex.Lock()
 	defer fake.getJSONMutex.Unlock()
 	fake.GetJSONStub = stub
 }
 
-func (fake *FakeClient) GetJSONArgsForCall(i int) (context.Context, interface{}, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) GetJSONArgsForCall(i int) (context.Context, interface{}, string, []http.ClientOpt) {
 	fake.getJSONMutex.RLock()
 	defer fake.getJSONMutex.RUnlock()
 	argsForCall := fake.getJSONArgsForCall[i]
@@ -525,13 +523,13 @@ func (fake *FakeClient) GetJSONReturnsOnCall(i int, result1 *httpa.Response, res
 	}{result1, result2}
 }
 
-func (fake *FakeClient) PatchBody(arg1 context.Context, arg2 string, arg3 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) PatchBody(arg1 context.Context, arg2 string, arg3 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.patchBodyMutex.Lock()
 	ret, specificReturn := fake.patchBodyReturnsOnCall[len(fake.patchBodyArgsForCall)]
 	fake.patchBodyArgsForCall = append(fake.patchBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}{arg1, arg2, arg3})
 	stub := fake.PatchBodyStub
 	fakeReturns := fake.patchBodyReturns
@@ -552,13 +550,13 @@ func (fake *FakeClient) PatchBodyCallCount() int {
 	return len(fake.patchBodyArgsForCall)
 }
 
-func (fake *FakeClient) PatchBodyCalls(stub func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) PatchBodyCalls(stub func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.patchBodyMutex.Lock()
 	defer fake.patchBodyMutex.Unlock()
 	fake.PatchBodyStub = stub
 }
 
-func (fake *FakeClient) PatchBodyArgsForCall(i int) (context.Context, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PatchBodyArgsForCall(i int) (context.Context, string, []http.ClientOpt) {
 	fake.patchBodyMutex.RLock()
 	defer fake.patchBodyMutex.RUnlock()
 	argsForCall := fake.patchBodyArgsForCall[i]
@@ -594,14 +592,14 @@ func (fake *FakeClient) PatchBodyReturnsOnCall(i int, result1 []byte, result2 *h
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) PatchJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) PatchJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.patchJSONMutex.Lock()
 	ret, specificReturn := fake.patchJSONReturnsOnCall[len(fake.patchJSONArgsForCall)]
 	fake.patchJSONArgsForCall = append(fake.patchJSONArgsForCall, struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.PatchJSONStub
 	fakeReturns := fake.patchJSONReturns
@@ -622,13 +620,13 @@ func (fake *FakeClient) PatchJSONCallCount() int {
 	return len(fake.patchJSONArgsForCall)
 }
 
-func (fake *FakeClient) PatchJSONCalls(stub func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) PatchJSONCalls(stub func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.patchJSONMutex.Lock()
 	defer fake.patchJSONMutex.Unlock()
 	fake.PatchJSONStub = stub
 }
 
-func (fake *FakeClient) PatchJSONArgsForCall(i int) (context.Context, interface{}, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PatchJSONArgsForCall(i int) (context.Context, interface{}, string, []http.ClientOpt) {
 	fake.patchJSONMutex.RLock()
 	defer fake.patchJSONMutex.RUnlock()
 	argsForCall := fake.patchJSONArgsForCall[i]
@@ -661,13 +659,13 @@ func (fake *FakeClient) PatchJSONReturnsOnCall(i int, result1 *httpa.Response, r
 	}{result1, result2}
 }
 
-func (fake *FakeClient) PostBody(arg1 context.Context, arg2 string, arg3 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) PostBody(arg1 context.Context, arg2 string, arg3 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.postBodyMutex.Lock()
 	ret, specificReturn := fake.postBodyReturnsOnCall[len(fake.postBodyArgsForCall)]
 	fake.postBodyArgsForCall = append(fake.postBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}{arg1, arg2, arg3})
 	stub := fake.PostBodyStub
 	fakeReturns := fake.postBodyReturns
@@ -688,13 +686,13 @@ func (fake *FakeClient) PostBodyCallCount() int {
 	return len(fake.postBodyArgsForCall)
 }
 
-func (fake *FakeClient) PostBodyCalls(stub func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) PostBodyCalls(stub func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.postBodyMutex.Lock()
 	defer fake.postBodyMutex.Unlock()
 	fake.PostBodyStub = stub
 }
 
-func (fake *FakeClient) PostBodyArgsForCall(i int) (context.Context, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PostBodyArgsForCall(i int) (context.Context, string, []http.ClientOpt) {
 	fake.postBodyMutex.RLock()
 	defer fake.postBodyMutex.RUnlock()
 	argsForCall := fake.postBodyArgsForCall[i]
@@ -730,14 +728,14 @@ func (fake *FakeClient) PostBodyReturnsOnCall(i int, result1 []byte, result2 *ht
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) PostJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) PostJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.postJSONMutex.Lock()
 	ret, specificReturn := fake.postJSONReturnsOnCall[len(fake.postJSONArgsForCall)]
 	fake.postJSONArgsForCall = append(fake.postJSONArgsForCall, struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.PostJSONStub
 	fakeReturns := fake.postJSONReturns
@@ -758,13 +756,13 @@ func (fake *FakeClient) PostJSONCallCount() int {
 	return len(fake.postJSONArgsForCall)
 }
 
-func (fake *FakeClient) PostJSONCalls(stub func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) PostJSONCalls(stub func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.postJSONMutex.Lock()
 	defer fake.postJSONMutex.Unlock()
 	fake.PostJSONStub = stub
 }
 
-func (fake *FakeClient) PostJSONArgsForCall(i int) (context.Context, interface{}, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PostJSONArgsForCall(i int) (context.Context, interface{}, string, []http.ClientOpt) {
 	fake.postJSONMutex.RLock()
 	defer fake.postJSONMutex.RUnlock()
 	argsForCall := fake.postJSONArgsForCall[i]
@@ -797,13 +795,13 @@ func (fake *FakeClient) PostJSONReturnsOnCall(i int, result1 *httpa.Response, re
 	}{result1, result2}
 }
 
-func (fake *FakeClient) PutBody(arg1 context.Context, arg2 string, arg3 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) PutBody(arg1 context.Context, arg2 string, arg3 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.putBodyMutex.Lock()
 	ret, specificReturn := fake.putBodyReturnsOnCall[len(fake.putBodyArgsForCall)]
 	fake.putBodyArgsForCall = append(fake.putBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
-		arg3 []func(req *retryablehttp.Request) error
+		arg3 []http.ClientOpt
 	}{arg1, arg2, arg3})
 	stub := fake.PutBodyStub
 	fakeReturns := fake.putBodyReturns
@@ -824,13 +822,13 @@ func (fake *FakeClient) PutBodyCallCount() int {
 	return len(fake.putBodyArgsForCall)
 }
 
-func (fake *FakeClient) PutBodyCalls(stub func(context.Context, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) PutBodyCalls(stub func(context.Context, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.putBodyMutex.Lock()
 	defer fake.putBodyMutex.Unlock()
 	fake.PutBodyStub = stub
 }
 
-func (fake *FakeClient) PutBodyArgsForCall(i int) (context.Context, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PutBodyArgsForCall(i int) (context.Context, string, []http.ClientOpt) {
 	fake.putBodyMutex.RLock()
 	defer fake.putBodyMutex.RUnlock()
 	argsForCall := fake.putBodyArgsForCall[i]
@@ -866,14 +864,14 @@ func (fake *FakeClient) PutBodyReturnsOnCall(i int, result1 []byte, result2 *htt
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) PutJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) PutJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.putJSONMutex.Lock()
 	ret, specificReturn := fake.putJSONReturnsOnCall[len(fake.putJSONArgsForCall)]
 	fake.putJSONArgsForCall = append(fake.putJSONArgsForCall, struct {
 		arg1 context.Context
 		arg2 interface{}
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.PutJSONStub
 	fakeReturns := fake.putJSONReturns
@@ -894,13 +892,13 @@ func (fake *FakeClient) PutJSONCallCount() int {
 	return len(fake.putJSONArgsForCall)
 }
 
-func (fake *FakeClient) PutJSONCalls(stub func(context.Context, interface{}, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) PutJSONCalls(stub func(context.Context, interface{}, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.putJSONMutex.Lock()
 	defer fake.putJSONMutex.Unlock()
 	fake.PutJSONStub = stub
 }
 
-func (fake *FakeClient) PutJSONArgsForCall(i int) (context.Context, interface{}, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) PutJSONArgsForCall(i int) (context.Context, interface{}, string, []http.ClientOpt) {
 	fake.putJSONMutex.RLock()
 	defer fake.putJSONMutex.RUnlock()
 	argsForCall := fake.putJSONArgsForCall[i]
@@ -933,14 +931,14 @@ func (fake *FakeClient) PutJSONReturnsOnCall(i int, result1 *httpa.Response, res
 	}{result1, result2}
 }
 
-func (fake *FakeClient) RequestBody(arg1 context.Context, arg2 string, arg3 string, arg4 ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error) {
+func (fake *FakeClient) RequestBody(arg1 context.Context, arg2 string, arg3 string, arg4 ...http.ClientOpt) ([]byte, *httpa.Response, error) {
 	fake.requestBodyMutex.Lock()
 	ret, specificReturn := fake.requestBodyReturnsOnCall[len(fake.requestBodyArgsForCall)]
 	fake.requestBodyArgsForCall = append(fake.requestBodyArgsForCall, struct {
 		arg1 context.Context
 		arg2 string
 		arg3 string
-		arg4 []func(req *retryablehttp.Request) error
+		arg4 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4})
 	stub := fake.RequestBodyStub
 	fakeReturns := fake.requestBodyReturns
@@ -961,13 +959,13 @@ func (fake *FakeClient) RequestBodyCallCount() int {
 	return len(fake.requestBodyArgsForCall)
 }
 
-func (fake *FakeClient) RequestBodyCalls(stub func(context.Context, string, string, ...func(req *retryablehttp.Request) error) ([]byte, *httpa.Response, error)) {
+func (fake *FakeClient) RequestBodyCalls(stub func(context.Context, string, string, ...http.ClientOpt) ([]byte, *httpa.Response, error)) {
 	fake.requestBodyMutex.Lock()
 	defer fake.requestBodyMutex.Unlock()
 	fake.RequestBodyStub = stub
 }
 
-func (fake *FakeClient) RequestBodyArgsForCall(i int) (context.Context, string, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) RequestBodyArgsForCall(i int) (context.Context, string, string, []http.ClientOpt) {
 	fake.requestBodyMutex.RLock()
 	defer fake.requestBodyMutex.RUnlock()
 	argsForCall := fake.requestBodyArgsForCall[i]
@@ -1003,7 +1001,7 @@ func (fake *FakeClient) RequestBodyReturnsOnCall(i int, result1 []byte, result2
 	}{result1, result2, result3}
 }
 
-func (fake *FakeClient) RequestJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 string, arg5 ...func(req *retryablehttp.Request) error) (*httpa.Response, error) {
+func (fake *FakeClient) RequestJSON(arg1 context.Context, arg2 interface{}, arg3 string, arg4 string, arg5 ...http.ClientOpt) (*httpa.Response, error) {
 	fake.requestJSONMutex.Lock()
 	ret, specificReturn := fake.requestJSONReturnsOnCall[len(fake.requestJSONArgsForCall)]
 	fake.requestJSONArgsForCall = append(fake.requestJSONArgsForCall, struct {
@@ -1011,7 +1009,7 @@ func (fake *FakeClient) RequestJSON(arg1 context.Context, arg2 interface{}, arg3
 		arg2 interface{}
 		arg3 string
 		arg4 string
-		arg5 []func(req *retryablehttp.Request) error
+		arg5 []http.ClientOpt
 	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.RequestJSONStub
 	fakeReturns := fake.requestJSONReturns
@@ -1032,13 +1030,13 @@ func (fake *FakeClient) RequestJSONCallCount() int {
 	return len(fake.requestJSONArgsForCall)
 }
 
-func (fake *FakeClient) RequestJSONCalls(stub func(context.Context, interface{}, string, string, ...func(req *retryablehttp.Request) error) (*httpa.Response, error)) {
+func (fake *FakeClient) RequestJSONCalls(stub func(context.Context, interface{}, string, string, ...http.ClientOpt) (*httpa.Response, error)) {
 	fake.requestJSONMutex.Lock()
 	defer fake.requestJSONMutex.Unlock()
 	fake.RequestJSONStub = stub
 }
 
-func (fake *FakeClient) RequestJSONArgsForCall(i int) (context.Context, interface{}, string, string, []func(req *retryablehttp.Request) error) {
+func (fake *FakeClient) RequestJSONArgsForCall(i int) (context.Context, interface{}, string, string, []http.ClientOpt) {
 	fake.requestJSONMutex.RLock()
 	defer fake.requestJSONMutex.RUnlock()
 	argsForCall := fake.requestJSONArgsForCall[i]
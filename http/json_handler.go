@@ -0,0 +1,344 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/logging/level"
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+// DefaultMaxJSONBody is the default limit on the size of JSONHandler request bodies
+const DefaultMaxJSONBody = 1 << 20
+
+// Validator is implemented by request types that JSONHandler validates after decoding
+type Validator interface {
+	Validate() error
+}
+
+// StatusError is an error with the HTTP status that a JSONHandler responds with
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+var _ errors.Error = (*StatusError)(nil)
+
+// WithStatus annotates err with the status that a JSONHandler responds with
+func WithStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+
+	return &StatusError{Status: status, Err: err}
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Msg() string {
+	if inner, ok := e.Err.(errors.Error); ok { //nolint:errorlint // only the direct cause
+		return inner.Msg()
+	}
+
+	return e.Err.Error()
+}
+
+func (e *StatusError) Data() []interface{} {
+	var inner errors.Error
+	if errors.As(e.Err, &inner) {
+		return inner.Data()
+	}
+
+	return nil
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ProblemFromError maps err to a status and an RFC 7807 problem document:
+//
+// - a *StatusError has its own status
+// - an *HTTPResponseError (from a call to another service) is a 502
+// - errors matching the status sentinels (e.g., ErrNotFound) have their status, and others
+// matching ErrClientError are a 400
+// - an *http.MaxBytesError is a 413, and context.DeadlineExceeded is a 504
+// - anything else is a 500
+//
+// For 4xx statuses, the detail is the error message and the error Data (see errors.Error)
+// become extension members. For 5xx statuses, only the status title is included.
+func ProblemFromError(err error) (int, *ProblemDetails) {
+	status := errorStatus(err)
+
+	p := &ProblemDetails{
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	if status >= 500 {
+		return status, p
+	}
+
+	var detailed errors.Error
+	if errors.As(err, &detailed) {
+		p.Detail = detailed.Msg()
+		p.Extensions = problemExtensions(detailed.Data())
+	} else {
+		p.Detail = err.Error()
+	}
+
+	return status, p
+}
+
+func errorStatus(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+
+	var respErr *HTTPResponseError
+	if errors.As(err, &respErr) {
+		return http.StatusBadGateway
+	}
+
+	for _, sentinel := range statusSentinelOrder {
+		if errors.Is(err, sentinel) {
+			return statusSentinels[sentinel]
+		}
+	}
+
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrClientError):
+		return http.StatusBadRequest
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func problemExtensions(data []interface{}) map[string]json.RawMessage {
+	if len(data) < 2 {
+		return nil
+	}
+
+	ext := make(map[string]json.RawMessage, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		b, err := json.Marshal(data[i+1])
+		if err != nil {
+			b, _ = json.Marshal(fmt.Sprint(data[i+1])) //nolint:errcheck // strings always marshal
+		}
+
+		ext[fmt.Sprint(data[i])] = b
+	}
+
+	return ext
+}
+
+// WriteJSON writes v as a JSON response with the provided status. Statuses that do
+// not permit a body (1xx, 204, and 304) are written without one, and v is ignored.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	if !statusAllowsBody(status) {
+		w.WriteHeader(status)
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal response")
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+
+	if _, err := w.Write(b); err != nil {
+		return errors.Wrap(err, "could not write response")
+	}
+
+	return nil
+}
+
+// statusAllowsBody reports whether a response with status may have a body (RFC 9110
+// sections 15.2, 15.3.5, and 15.4.5)
+func statusAllowsBody(status int) bool {
+	switch {
+	case status >= 100 && status < 200:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	default:
+		return true
+	}
+}
+
+// WriteProblem writes p as a problem+json response, filling in the status and instance (the
+// request path) if they are unset, and adding the request ID (if any) as an extension
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *ProblemDetails) error {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+
+	if rid, ok := request.GetRequestID(r.Context()); ok {
+		if _, exists := p.Extensions["request_id"]; !exists {
+			if p.Extensions == nil {
+				p.Extensions = map[string]json.RawMessage{}
+			}
+			p.Extensions["request_id"], _ = json.Marshal(rid) //nolint:errcheck // strings always marshal
+		}
+	}
+
+	b, err := p.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal problem")
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	if _, err := w.Write(b); err != nil {
+		return errors.Wrap(err, "could not write problem")
+	}
+
+	return nil
+}
+
+// WriteError writes the problem+json response for err (see ProblemFromError), logging
+// 5xx errors with the logger from the request context
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, p := ProblemFromError(err)
+	if status >= 500 {
+		level.Error(logging.FromContext(r.Context())).Err("request failed", err, "status", status)
+	}
+
+	if werr := WriteProblem(w, r, p); werr != nil {
+		level.Error(logging.FromContext(r.Context())).Err("could not write error response", werr)
+	}
+}
+
+type handlerRequestKey struct{}
+
+// HandlerRequest is the request being handled by a JSONHandler (e.g., for path values)
+func HandlerRequest(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(handlerRequestKey{}).(*http.Request)
+	return r, ok
+}
+
+type jsonHandlerConfig struct {
+	maxBody int64
+	status  int
+}
+
+// JSONHandlerOpt configures a JSONHandler
+type JSONHandlerOpt func(*jsonHandlerConfig)
+
+// WithMaxRequestBody limits request bodies to n bytes (default DefaultMaxJSONBody)
+func WithMaxRequestBody(n int64) JSONHandlerOpt {
+	return func(cfg *jsonHandlerConfig) {
+		cfg.maxBody = n
+	}
+}
+
+// WithSuccessStatus sets the status of successful responses (default 200)
+func WithSuccessStatus(status int) JSONHandlerOpt {
+	return func(cfg *jsonHandlerConfig) {
+		cfg.status = status
+	}
+}
+
+// JSONHandler adapts fn to an http.Handler. The request body (if any) is decoded into a Req
+// with the json package (a pointer Req is never nil, even without a body) and validated
+// (if Req is a Validator), and the Resp returned by fn
+// is encoded as the response. Errors are written as problem+json (see ProblemFromError):
+// a malformed body is a 400, the wrong content type a 415, too large a body a 413, and a
+// failed validation a 422.
+func JSONHandler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error), opts ...JSONHandlerOpt) http.Handler {
+	cfg := jsonHandlerConfig{
+		maxBody: DefaultMaxJSONBody,
+		status:  http.StatusOK,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decodeJSONRequest[Req](w, r, cfg.maxBody)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		resp, err := fn(context.WithValue(r.Context(), handlerRequestKey{}, r), req)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		if err := WriteJSON(w, cfg.status, resp); err != nil {
+			level.Error(logging.FromContext(r.Context())).Err("could not write response", err)
+		}
+	})
+}
+
+func decodeJSONRequest[Req any](w http.ResponseWriter, r *http.Request, maxBody int64) (Req, error) {
+	var req Req
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		return req, errors.Wrap(err, "could not read request body")
+	}
+
+	if len(body) > 0 {
+		if ct := r.Header.Get("Content-Type"); ct != "" && !isJSONMediaType(ct) {
+			return req, WithStatus(errors.WithDetails(errors.New("unsupported content type"), "content_type", ct), http.StatusUnsupportedMediaType)
+		}
+
+		if err := json.Unmarshal(body, &req); err != nil {
+			return req, WithStatus(errors.Wrap(err, "malformed request body"), http.StatusBadRequest)
+		}
+	}
+
+	// an empty (or null) body leaves a pointer Req nil, which Validate methods do not expect
+	if rv := reflect.ValueOf(&req).Elem(); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		rv.Set(reflect.New(rv.Type().Elem()))
+	}
+
+	if v, ok := any(&req).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return req, WithStatus(err, http.StatusUnprocessableEntity)
+		}
+	} else if v, ok := any(req).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return req, WithStatus(err, http.StatusUnprocessableEntity)
+		}
+	}
+
+	return req, nil
+}
+
+func isJSONMediaType(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mt == ContentTypeJSON || strings.HasSuffix(mt, "+json")
+}
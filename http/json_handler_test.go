@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+func (g *greetRequest) Validate() error {
+	if g.Name == "" {
+		return errors.WithDetails(errors.New("name is required"), "field", "name")
+	}
+
+	return nil
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+	ID       string `json:"id"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	t.Parallel()
+
+	h := JSONHandler(func(ctx context.Context, req greetRequest) (greetResponse, error) {
+		switch req.Name {
+		case "missing":
+			return greetResponse{}, errors.Wrap(ErrNotFound, "no such person", "name", req.Name)
+		case "boom":
+			return greetResponse{}, errors.New("database password is hunter2")
+		case "teapot":
+			return greetResponse{}, WithStatus(errors.New("short and stout"), http.StatusTeapot)
+		}
+
+		r, ok := HandlerRequest(ctx)
+		assert.True(t, ok)
+
+		return greetResponse{Greeting: "hello " + req.Name, ID: r.PathValue("id")}, nil
+	}, WithMaxRequestBody(64), WithSuccessStatus(http.StatusCreated))
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /greet/{id}", h)
+
+	do := func(contentType, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/greet/7", strings.NewReader(body))
+		req = req.WithContext(request.NewRequestContextWithRequestID(req.Context(), "rid-1"))
+		req.Header.Set("Content-Type", contentType)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		var out map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out), rec.Body.String())
+
+		return rec, out
+	}
+
+	rec, out := do(ContentTypeJSON, `{"name":"ada"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, ContentTypeJSON, rec.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]interface{}{"greeting": "hello ada", "id": "7"}, out)
+
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		detail      string
+		extensions  map[string]interface{}
+	}{
+		{name: "malformed", contentType: ContentTypeJSON, body: `{"name":`, status: http.StatusBadRequest},
+		{name: "content type", contentType: "text/plain", body: `name=ada`, status: http.StatusUnsupportedMediaType, extensions: map[string]interface{}{"content_type": "text/plain"}},
+		{name: "too large", contentType: ContentTypeJSON, body: `{"name":"` + strings.Repeat("a", 100) + `"}`, status: http.StatusRequestEntityTooLarge},
+		{name: "invalid", contentType: ContentTypeJSON, body: `{}`, status: http.StatusUnprocessableEntity, detail: "name is required", extensions: map[string]interface{}{"field": "name"}},
+		{name: "sentinel", contentType: ContentTypeJSON, body: `{"name":"missing"}`, status: http.StatusNotFound, extensions: map[string]interface{}{"name": "missing"}},
+		{name: "status", contentType: ContentTypeJSON, body: `{"name":"teapot"}`, status: http.StatusTeapot, detail: "short and stout"},
+		{name: "internal", contentType: "application/merge-patch+json", body: `{"name":"boom"}`, status: http.StatusInternalServerError},
+	} {
+		rec, out := do(tc.contentType, tc.body)
+		assert.Equal(t, tc.status, rec.Code, tc.name)
+		assert.Equal(t, ContentTypeProblemJSON, rec.Header().Get("Content-Type"), tc.name)
+		assert.Equal(t, float64(tc.status), out["status"], tc.name)
+		assert.Equal(t, http.StatusText(tc.status), out["title"], tc.name)
+		assert.Equal(t, "/greet/7", out["instance"], tc.name)
+		assert.Equal(t, "rid-1", out["request_id"], tc.name)
+
+		if tc.detail != "" {
+			assert.Equal(t, tc.detail, out["detail"], tc.name)
+		}
+
+		for k, v := range tc.extensions {
+			assert.Equal(t, v, out[k], tc.name)
+		}
+	}
+
+	_, out = do(ContentTypeJSON, `{"name":"boom"}`)
+	assert.NotContains(t, out, "detail", "5xx details are not exposed")
+}
+
+func TestJSONHandler_pointerRequest(t *testing.T) {
+	t.Parallel()
+
+	h := JSONHandler(func(_ context.Context, req *greetRequest) (greetResponse, error) {
+		return greetResponse{Greeting: "hello " + req.Name}, nil
+	})
+
+	for _, body := range []string{"", "null"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "body %q is validated, not a nil pointer", body)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"ada"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestJSONHandler_noContent(t *testing.T) {
+	t.Parallel()
+
+	var called bool
+	h := JSONHandler(func(context.Context, struct{}) (greetResponse, error) {
+		called = true
+		return greetResponse{Greeting: "ignored"}, nil
+	}, WithSuccessStatus(http.StatusNoContent))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/greet/7", http.NoBody))
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String(), "204 responses have no body")
+	assert.Empty(t, rec.Header().Get("Content-Type"))
+
+	for _, status := range []int{http.StatusNotModified, http.StatusContinue} {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, WriteJSON(rec, status, greetResponse{Greeting: "ignored"}))
+		assert.Empty(t, rec.Body.String(), "%d responses have no body", status)
+	}
+}
+
+func TestProblemFromError_sentinelOrder(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("%w: %w", ErrBadRequest, ErrNotFound)
+	for range 20 {
+		status, _ := ProblemFromError(err)
+		assert.Equal(t, http.StatusNotFound, status)
+	}
+}
+
+func TestProblemDetails_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	p := &ProblemDetails{
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Extensions: map[string]json.RawMessage{
+			"status": json.RawMessage(`"ignored"`),
+			"extra":  json.RawMessage(`[1,2]`),
+		},
+	}
+
+	b, err := p.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Not Found","status":404,"extra":[1,2]}`, string(b))
+
+	resp := &http.Response{Header: http.Header{"Content-Type": {ContentTypeProblemJSON}}}
+	detail, ok := DecodeProblemDetails(resp, b)
+	assert.True(t, ok)
+	assert.Equal(t, "Not Found", detail.Summary())
+}
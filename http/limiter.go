@@ -0,0 +1,252 @@
+package http
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/logging/level"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// LimitOptions configures client-side throttling of a TelemeterClient, per host. Every
+// attempt (including retries) waits for a token and an in-flight slot, giving up if the
+// request context is done.
+//
+// - Rate is the number of requests per second (0 is unlimited)
+// - Burst is the size of the token bucket (default Rate, rounded up, and at least 1)
+// - MaxInFlight is the number of concurrent requests, held until the response body is closed (0 is unlimited)
+// - Throttle is how long to pause a host after a 429 response without a Retry-After header,
+// doubling for each consecutive 429 (default 1s)
+// - MaxThrottle caps the pause after a 429 response (default 1m)
+type LimitOptions struct {
+	Rate        float64
+	Burst       int
+	MaxInFlight int
+	Throttle    time.Duration
+	MaxThrottle time.Duration
+}
+
+// limiters holds the throttling state per host
+type limiters struct {
+	opts   LimitOptions
+	logger logging.Logger
+	wait   telemetry.Float64Histogram
+	mu     sync.Mutex
+	byHost map[string]*limiter
+}
+
+type limiter struct {
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	pausedUntil  time.Time
+	throttleNext time.Duration
+
+	slots chan struct{}
+}
+
+func newLimiters(opts LimitOptions, logger logging.Logger, tel *telemetry.Telemeter) *limiters {
+	if opts.Burst <= 0 {
+		opts.Burst = max(int(math.Ceil(opts.Rate)), 1)
+	}
+
+	if opts.Throttle <= 0 {
+		opts.Throttle = time.Second
+	}
+
+	if opts.MaxThrottle <= 0 {
+		opts.MaxThrottle = time.Minute
+	}
+
+	l := &limiters{
+		opts:   opts,
+		logger: logger,
+		byHost: map[string]*limiter{},
+	}
+
+	wait, err := tel.Meter("http").Float64Histogram(
+		"http.client.limiter.wait",
+		telemetry.WithDescription("Time spent waiting for the client-side rate and concurrency limits"),
+		telemetry.WithUnit("s"),
+	)
+	if err != nil {
+		level.Error(logger).Err("could not create limiter wait histogram", err)
+	}
+	l.wait = wait
+
+	return l
+}
+
+func (l *limiters) forHost(host string) *limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lim, ok := l.byHost[host]
+	if !ok {
+		lim = &limiter{
+			tokens:       float64(l.opts.Burst),
+			last:         time.Now(),
+			throttleNext: l.opts.Throttle,
+		}
+
+		if l.opts.MaxInFlight > 0 {
+			lim.slots = make(chan struct{}, l.opts.MaxInFlight)
+		}
+
+		l.byHost[host] = lim
+	}
+
+	return lim
+}
+
+// acquire blocks until a request to host may be made, returning a function that
+// releases its in-flight slot
+func (l *limiters) acquire(ctx context.Context, host string) (release func(), err error) {
+	lim := l.forHost(host)
+	start := time.Now()
+
+	defer func() {
+		if l.wait != nil {
+			l.wait.Record(ctx, time.Since(start).Seconds(), telemetry.WithMetricAttributes(
+				telemetry.KVString("host", host),
+				telemetry.KVBool("acquired", err == nil),
+			))
+		}
+	}()
+
+	release = func() {}
+	if lim.slots != nil {
+		select {
+		case lim.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "could not acquire in-flight slot", "host", host)
+		}
+
+		var once sync.Once
+		release = func() { once.Do(func() { <-lim.slots }) }
+	}
+
+	for {
+		wait := lim.take(l.opts)
+		if wait <= 0 {
+			return release, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, errors.Wrap(ctx.Err(), "could not acquire rate limit token", "host", host)
+		}
+	}
+}
+
+// take consumes a token if one is available, otherwise reporting how long to wait
+func (lim *limiter) take(opts LimitOptions) time.Duration {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(lim.pausedUntil) {
+		return lim.pausedUntil.Sub(now)
+	}
+
+	if opts.Rate <= 0 {
+		return 0
+	}
+
+	lim.tokens = math.Min(lim.tokens+now.Sub(lim.last).Seconds()*opts.Rate, float64(opts.Burst))
+	lim.last = now
+
+	if lim.tokens >= 1 {
+		lim.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - lim.tokens) / opts.Rate * float64(time.Second))
+}
+
+// observe pauses the host after a 429 response, for the Retry-After duration if present,
+// and otherwise for an exponentially increasing amount of time
+func (l *limiters) observe(host string, resp *http.Response) {
+	lim := l.forHost(host)
+
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		lim.throttleNext = l.opts.Throttle
+		return
+	}
+
+	pause, ok := retryAfter(resp)
+	if !ok {
+		pause = lim.throttleNext
+		lim.throttleNext = min(2*lim.throttleNext, l.opts.MaxThrottle)
+	}
+	pause = min(pause, l.opts.MaxThrottle)
+
+	if until := time.Now().Add(pause); until.After(lim.pausedUntil) {
+		lim.pausedUntil = until
+	}
+
+	level.Warn(l.logger).Message("throttling host after 429 response", "host", host, "pause", pause.String())
+}
+
+// limitingTransport applies the limiters to every attempt made through base
+type limitingTransport struct {
+	base     http.RoundTripper
+	limiters *limiters
+}
+
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiters.acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return resp, err
+	}
+
+	t.limiters.observe(req.URL.Host, resp)
+
+	if resp.Body == nil {
+		release()
+		return resp, nil
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// releasingBody releases an in-flight slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// closeRequestBody closes the body of a request that will not be sent, as a
+// RoundTripper must
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+func TestTelemeterClient_rateLimit(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.SetLimits(LimitOptions{Rate: 50, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, _, err := c.GetBody(context.Background(), srv.URL)
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "5 requests after the burst at 50/s")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	c.SetLimits(LimitOptions{Rate: 0.001, Burst: 1})
+	_, _, err := c.GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+
+	_, _, err = c.GetBody(ctx, srv.URL)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestTelemeterClient_maxInFlight(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.SetLimits(LimitOptions{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := c.GetBody(context.Background(), srv.URL)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int64(2))
+}
+
+func TestLimiters_throttle(t *testing.T) {
+	t.Parallel()
+
+	l := newLimiters(LimitOptions{Throttle: 20 * time.Millisecond, MaxThrottle: 30 * time.Millisecond}, logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t))
+	tooMany := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+
+	l.observe("h", tooMany)
+	assert.InDelta(t, 20*time.Millisecond, l.forHost("h").take(l.opts), float64(5*time.Millisecond))
+
+	l.observe("h", tooMany)
+	assert.InDelta(t, 30*time.Millisecond, l.forHost("h").take(l.opts), float64(5*time.Millisecond), "doubled and capped")
+
+	start := time.Now()
+	release, err := l.acquire(context.Background(), "h")
+	assert.NoError(t, err)
+	release()
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	l.observe("h", &http.Response{StatusCode: http.StatusOK})
+	assert.Equal(t, 20*time.Millisecond, l.forHost("h").throttleNext, "success resets the throttle")
+
+	tooMany.Header.Set("Retry-After", "3600")
+	l.observe("other", tooMany)
+	assert.InDelta(t, 30*time.Millisecond, l.forHost("other").take(l.opts), float64(5*time.Millisecond), "Retry-After is capped too")
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// Metric names recorded by TelemeterRoundTripper and TelemeterClient
+const (
+	MetricClientRequestDuration = "http.client.request.duration"
+	MetricClientActiveRequests  = "http.client.active_requests"
+	MetricClientRetries         = "http.client.retries"
+)
+
+// WithRouteTemplate labels the request's metrics and spans with route (e.g., "/users/{id}")
+// rather than leaving the path out entirely
+func WithRouteTemplate(route string) ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.route = route
+		})
+
+		return nil
+	}
+}
+
+// ContextWithRouteTemplate is WithRouteTemplate for requests sent through a
+// TelemeterRoundTripper directly
+func ContextWithRouteTemplate(ctx context.Context, route string) context.Context {
+	cfg := &requestConfig{}
+	if existing := requestConfigFrom(ctx); existing != nil {
+		*cfg = *existing
+	}
+	cfg.route = route
+
+	return context.WithValue(ctx, requestConfigKey{}, cfg)
+}
+
+// clientMetrics are the instruments for the client RED metrics. Instrument creation
+// only fails for invalid names or options, and still returns a usable instrument.
+type clientMetrics struct {
+	duration telemetry.Float64Histogram
+	active   telemetry.Int64UpDownCounter
+	retries  telemetry.Int64Counter
+}
+
+func newClientMetrics(tel *telemetry.Telemeter) *clientMetrics {
+	meter := tel.Meter("http")
+
+	duration, _ := meter.Float64Histogram(MetricClientRequestDuration, //nolint:errcheck // see clientMetrics
+		telemetry.WithDescription("Duration of each HTTP client request attempt"),
+		telemetry.WithUnit("s"),
+	)
+
+	active, _ := meter.Int64UpDownCounter(MetricClientActiveRequests, //nolint:errcheck // see clientMetrics
+		telemetry.WithDescription("Number of HTTP client request attempts in flight"),
+		telemetry.WithUnit("{request}"),
+	)
+
+	retries, _ := meter.Int64Counter(MetricClientRetries, //nolint:errcheck // see clientMetrics
+		telemetry.WithDescription("Number of HTTP client request attempts that were retries"),
+		telemetry.WithUnit("{request}"),
+	)
+
+	return &clientMetrics{
+		duration: duration,
+		active:   active,
+		retries:  retries,
+	}
+}
+
+// requestMetricAttributes are the low-cardinality attributes for req: the method, the
+// host, and the route template (if any)
+func requestMetricAttributes(req *http.Request) []telemetry.KeyValue {
+	attrs := make([]telemetry.KeyValue, 0, 4)
+	attrs = append(attrs,
+		telemetry.KVString("http.request.method", metricMethod(req.Method)),
+		telemetry.KVString("server.address", req.URL.Hostname()),
+	)
+
+	if cfg := requestConfigFrom(req.Context()); cfg != nil && cfg.route != "" {
+		attrs = append(attrs, telemetry.KVString("http.route", cfg.route))
+	}
+
+	return attrs
+}
+
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "_OTHER"
+	}
+}
+
+// statusClass is "2xx" and so on, or "error" if there was no response
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
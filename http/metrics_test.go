@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+func TestTelemeterClient_metrics(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(io.Discard))
+	assert.NoError(t, err)
+
+	mp := newRecordingMeterProvider()
+	c := NewTelemeterClient(logging.NewJSONFileLogger(io.Discard), telemetry.NewTelemeter("test", "v0", "test_instance", exp, mp, 1.0))
+	one := 1
+	c.ConfigureRetries(RetryOptions{RetryWaitMin: 1, RetryWaitMax: 1, RetryMax: &one})
+
+	_, _, err = c.GetBody(context.Background(), srv.URL+"/users/42", WithRouteTemplate("/users/{id}"))
+	assert.NoError(t, err)
+
+	durations := mp.recorded(MetricClientRequestDuration)
+	if assert.Len(t, durations, 2) {
+		var classes []string
+		for _, d := range durations {
+			class, _ := d.attrs.Value("http.response.status_class")
+			classes = append(classes, class.AsString())
+
+			route, _ := d.attrs.Value("http.route")
+			assert.Equal(t, "/users/{id}", route.AsString())
+
+			method, _ := d.attrs.Value("http.request.method")
+			assert.Equal(t, http.MethodGet, method.AsString())
+
+			assert.False(t, d.attrs.HasValue("url.full"), "no high-cardinality attributes")
+		}
+		assert.Equal(t, []string{"5xx", "2xx"}, classes)
+	}
+
+	retries := mp.recorded(MetricClientRetries)
+	assert.Len(t, retries, 1)
+
+	var active float64
+	for _, v := range mp.recorded(MetricClientActiveRequests) {
+		active += v.value
+	}
+	assert.Equal(t, float64(0), active, "in-flight returns to zero")
+}
@@ -4,8 +4,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
 )
 
+const ContentTypeJSON = "application/json"
+
 type ClientOpt = func(req *Request) error
 
 func WithBody(body io.ReadCloser) ClientOpt {
@@ -14,6 +21,44 @@ func WithBody(body io.ReadCloser) ClientOpt {
 	}
 }
 
+// WithJSONBody marshals v with the json package and uses it as the request body, setting
+// the Content-Type (and Accept, if not already set) headers. The body is replayed on retries.
+func WithJSONBody(v interface{}) ClientOpt {
+	return func(req *Request) error {
+		body, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal request body")
+		}
+
+		return setJSONBody(req, body)
+	}
+}
+
+// WithProtoJSONBody is WithJSONBody for protobuf messages, using protojson
+func WithProtoJSONBody(m proto.Message) ClientOpt {
+	return func(req *Request) error {
+		body, err := json.ProtoMarshalAppend(nil, m)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal request body")
+		}
+
+		return setJSONBody(req, body)
+	}
+}
+
+func setJSONBody(req *Request, body []byte) error {
+	if err := req.SetBody(body); err != nil {
+		return errors.Wrap(err, "could not set request body")
+	}
+
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", ContentTypeJSON)
+	}
+
+	return nil
+}
+
 func WithHeaders(headers http.Header) ClientOpt {
 	return func(req *Request) error {
 		for k, vs := range headers {
@@ -46,3 +91,15 @@ func WithResponseHandler(fn ResponseHandlerFunc) ClientOpt {
 		return nil
 	}
 }
+
+// WithErrorDecoders sets the decoders tried, in order, on the body of a 4xx/5xx response
+// to this request, overriding the client's decoders
+func WithErrorDecoders(decoders ...ErrorDecoder) ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.errorDecoders = decoders
+		})
+
+		return nil
+	}
+}
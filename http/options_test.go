@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type echoBody struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestWithJSONBody(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		assert.Equal(t, ContentTypeJSON, r.Header.Get("Accept"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		// fail the first attempt so the body has to be replayed
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	one := 1
+	c.ConfigureRetries(RetryOptions{RetryMax: &one})
+
+	var got echoBody
+	resp, err := c.PostJSON(context.Background(), &got, srv.URL, WithJSONBody(echoBody{Name: "foo", Count: 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, echoBody{Name: "foo", Count: 2}, got)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithProtoJSONBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	body, _, err := newTestClient(t).PutBody(context.Background(), srv.URL, WithProtoJSONBody(wrapperspb.String("hello")))
+	assert.NoError(t, err)
+	assert.Equal(t, `"hello"`, string(body))
+}
@@ -0,0 +1,309 @@
+package http
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+var (
+	// ErrMaxPages is matched (with errors.Is) when pagination stops at Pagination.MaxPages
+	// while there are more pages
+	ErrMaxPages = errors.New("too many pages")
+	// ErrCrossOriginPage is matched (with errors.Is) when the next page is on another origin,
+	// and Pagination.AllowCrossOrigin is not set
+	ErrCrossOriginPage = errors.New("next page is on another origin")
+)
+
+// DefaultMaxPages is the default limit on the number of pages fetched by Paginate
+const DefaultMaxPages = 100
+
+// PageState describes a page that has been fetched
+type PageState[Page any] struct {
+	// URL is the URL the page was fetched from
+	URL string
+	// Number is the position of the page, starting at 1
+	Number   int
+	Response *http.Response
+	Page     *Page
+	// Items is the number of items in the page
+	Items int
+}
+
+// PageStrategy determines the URL of the page after the provided one, or "" if it is the last
+type PageStrategy[Page any] func(state *PageState[Page]) (string, error)
+
+// Pagination describes how to page through a list endpoint
+//
+// - Items extracts the items from a page
+// - Next is the strategy for finding the next page (e.g., LinkHeaderPages)
+// - MaxPages limits the number of pages fetched (default DefaultMaxPages)
+// - AllowCrossOrigin follows next pages to another origin (scheme, host, and port), which
+// are sent the same opts (including any credentials)
+type Pagination[Page, Item any] struct {
+	Items            func(page *Page) []Item
+	Next             PageStrategy[Page]
+	MaxPages         int
+	AllowCrossOrigin bool
+}
+
+// Paginate fetches the pages of a list endpoint with client.RequestJSON (using GET, and the
+// opts for every page), starting from reqURL, and yields their items. Pages are fetched as the
+// items are consumed. Iteration ends after yielding an error, including ctx errors,
+// ErrMaxPages, and ErrCrossOriginPage.
+func Paginate[Page, Item any](ctx context.Context, client Client, reqURL string, p Pagination[Page, Item], opts ...ClientOpt) iter.Seq2[Item, error] {
+	maxPages := p.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	return func(yield func(Item, error) bool) {
+		var zero Item
+
+		if p.Items == nil || p.Next == nil {
+			yield(zero, errors.New("pagination requires Items and Next"))
+			return
+		}
+
+		next := reqURL
+		for number := 1; next != ""; number++ {
+			if number > maxPages {
+				yield(zero, errors.Wrap(ErrMaxPages, "", "max_pages", maxPages, "next", next))
+				return
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var page Page
+			resp, err := client.RequestJSON(ctx, &page, http.MethodGet, next, opts...)
+			if err != nil {
+				yield(zero, errors.Wrap(err, "could not fetch page", "page", number, "url", next))
+				return
+			}
+
+			items := p.Items(&page)
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			current := next
+			next, err = p.Next(&PageState[Page]{
+				URL:      current,
+				Number:   number,
+				Response: resp,
+				Page:     &page,
+				Items:    len(items),
+			})
+			if err != nil {
+				yield(zero, errors.Wrap(err, "could not find next page", "page", number))
+				return
+			}
+
+			if next != "" && !p.AllowCrossOrigin {
+				if err := checkSameOrigin(resp, current, next); err != nil {
+					yield(zero, err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// LinkHeaderPages follows the rel="next" links of RFC 8288 (formerly RFC 5988) Link headers
+func LinkHeaderPages[Page any]() PageStrategy[Page] {
+	return func(state *PageState[Page]) (string, error) {
+		if state.Response == nil {
+			return "", nil
+		}
+
+		for _, header := range state.Response.Header.Values("Link") {
+			if target, ok := linkWithRel(header, "next"); ok {
+				return resolveAgainst(state.URL, target)
+			}
+		}
+
+		return "", nil
+	}
+}
+
+// CursorPages sets the query parameter param to the cursor from each page, stopping when
+// the cursor is empty
+func CursorPages[Page any](param string, cursor func(page *Page) string) PageStrategy[Page] {
+	return func(state *PageState[Page]) (string, error) {
+		c := cursor(state.Page)
+		if c == "" {
+			return "", nil
+		}
+
+		return withQueryParam(state.URL, param, c)
+	}
+}
+
+// PageNumberPages increments the query parameter param (treated as 1 if absent) for each
+// page, stopping at a page without items
+func PageNumberPages[Page any](param string) PageStrategy[Page] {
+	return func(state *PageState[Page]) (string, error) {
+		if state.Items == 0 {
+			return "", nil
+		}
+
+		current, err := intQueryParam(state.URL, param, 1)
+		if err != nil {
+			return "", err
+		}
+
+		return withQueryParam(state.URL, param, strconv.Itoa(current+1))
+	}
+}
+
+// OffsetPages advances the query parameter param (treated as 0 if absent) by the number of
+// items in each page, stopping at a page without items
+func OffsetPages[Page any](param string) PageStrategy[Page] {
+	return func(state *PageState[Page]) (string, error) {
+		if state.Items == 0 {
+			return "", nil
+		}
+
+		current, err := intQueryParam(state.URL, param, 0)
+		if err != nil {
+			return "", err
+		}
+
+		return withQueryParam(state.URL, param, strconv.Itoa(current+state.Items))
+	}
+}
+
+// linkWithRel finds the target of the link with the provided relation type in a Link header
+// value, e.g. `<https://api/items?page=2>; rel="next", <https://api/items?page=9>; rel="last"`
+func linkWithRel(header, rel string) (string, bool) {
+	for _, link := range splitLinks(header) {
+		target, params, ok := strings.Cut(link, ";")
+		target = strings.TrimSpace(target)
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+				continue
+			}
+
+			for _, r := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if strings.EqualFold(r, rel) {
+					return target[1 : len(target)-1], true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// splitLinks splits a Link header value on the commas between links, but not those inside
+// a target or a quoted parameter
+func splitLinks(header string) []string {
+	var (
+		links   []string
+		start   int
+		inURI   bool
+		inQuote bool
+	)
+
+	for i := range len(header) {
+		switch c := header[i]; {
+		case c == '<' && !inQuote:
+			inURI = true
+		case c == '>' && !inQuote:
+			inURI = false
+		case c == '"' && !inURI:
+			inQuote = !inQuote
+		case c == ',' && !inURI && !inQuote:
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(links, header[start:])
+}
+
+func resolveAgainst(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse url", "url", base)
+	}
+
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse link", "link", ref)
+	}
+
+	return b.ResolveReference(r).String(), nil
+}
+
+// checkSameOrigin returns an error matching ErrCrossOriginPage if next is on another
+// origin than the page at current, which was fetched with resp (resolving current if it
+// was relative to a base URL)
+func checkSameOrigin(resp *http.Response, current, next string) error {
+	cu, err := url.Parse(current)
+	if err != nil {
+		return errors.Wrap(err, "could not parse url", "url", current)
+	}
+
+	if resp != nil && resp.Request != nil {
+		cu = resp.Request.URL
+	}
+
+	nu, err := url.Parse(next)
+	if err != nil {
+		return errors.Wrap(err, "could not parse url", "url", next)
+	}
+
+	if nu = cu.ResolveReference(nu); crossOrigin(cu, nu) {
+		return errors.Wrap(ErrCrossOriginPage, "", "from", redirectLocation(cu), "to", redirectLocation(nu))
+	}
+
+	return nil
+}
+
+func withQueryParam(reqURL, param, value string) (string, error) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse url", "url", reqURL)
+	}
+
+	query := u.Query()
+	query.Set(param, value)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func intQueryParam(reqURL, param string, def int) (int, error) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not parse url", "url", reqURL)
+	}
+
+	v := u.Query().Get(param)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not parse page parameter", "param", param, "value", v)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+type itemsPage struct {
+	Items      []int  `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func collect[T any](seq func(func(T, error) bool)) ([]T, error) {
+	var out []T
+	for v, err := range seq {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, v)
+	}
+
+	return out, nil
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	// 7 items, served 3 to a page
+	const total, size = 7, 3
+	pageFrom := func(offset int) itemsPage {
+		p := itemsPage{Items: []int{}}
+		for i := offset; i < min(offset+size, total); i++ {
+			p.Items = append(p.Items, i)
+		}
+		if offset+size < total {
+			p.NextCursor = "c" + strconv.Itoa(offset+size)
+		}
+		return p
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset+size < total {
+			w.Header().Add("Link", fmt.Sprintf(`</link?offset=%d>; rel="next", </link?offset=6>; rel="last"`, offset+size))
+		}
+		_ = WriteJSON(w, http.StatusOK, pageFrom(offset))
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		offset := 0
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			offset, _ = strconv.Atoi(c[1:])
+		}
+		_ = WriteJSON(w, http.StatusOK, pageFrom(offset))
+	})
+	mux.HandleFunc("/number", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		_ = WriteJSON(w, http.StatusOK, pageFrom((max(page, 1)-1)*size).Items)
+	})
+	mux.HandleFunc("/offset", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		_ = WriteJSON(w, http.StatusOK, pageFrom(offset).Items)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+	want := []int{0, 1, 2, 3, 4, 5, 6}
+	pageItems := func(p *itemsPage) []int { return p.Items }
+	sliceItems := func(p *[]int) []int { return *p }
+
+	got, err := collect(Paginate(ctx, c, srv.URL+"/link", Pagination[itemsPage, int]{Items: pageItems, Next: LinkHeaderPages[itemsPage]()}))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = collect(Paginate(ctx, c, srv.URL+"/cursor", Pagination[itemsPage, int]{
+		Items: pageItems,
+		Next:  CursorPages("cursor", func(p *itemsPage) string { return p.NextCursor }),
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = collect(Paginate(ctx, c, srv.URL+"/number", Pagination[[]int, int]{Items: sliceItems, Next: PageNumberPages[[]int]("page")}))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = collect(Paginate(ctx, c, srv.URL+"/offset?limit=3", Pagination[[]int, int]{Items: sliceItems, Next: OffsetPages[[]int]("offset")}))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = collect(Paginate(ctx, c, srv.URL+"/link", Pagination[itemsPage, int]{Items: pageItems, Next: LinkHeaderPages[itemsPage](), MaxPages: 2}))
+	assert.True(t, errors.Is(err, ErrMaxPages))
+	assert.Equal(t, want[:6], got)
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var seen []int
+	for v, err := range Paginate(cctx, c, srv.URL+"/link", Pagination[itemsPage, int]{Items: pageItems, Next: LinkHeaderPages[itemsPage]()}) {
+		if err != nil {
+			assert.True(t, errors.Is(err, context.Canceled))
+			break
+		}
+		seen = append(seen, v)
+		cancel()
+	}
+	assert.Equal(t, want[:3], seen, "the current page is finished, but no more are fetched")
+}
+
+func TestLinkWithRel(t *testing.T) {
+	t.Parallel()
+
+	header := `<https://api.example.com/items?a=1,2>; rel="prev first", <https://api.example.com/items?page=3>; title="a, b"; rel=next`
+
+	next, ok := linkWithRel(header, "next")
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.example.com/items?page=3", next)
+
+	prev, ok := linkWithRel(header, "first")
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.example.com/items?a=1,2", prev)
+
+	_, ok = linkWithRel(header, "last")
+	assert.False(t, ok)
+}
+
+func TestPaginate_crossOrigin(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "credentials are not sent to another origin")
+		_ = WriteJSON(w, http.StatusOK, itemsPage{Items: []int{9}})
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "<"+other.URL+"/items>; rel=next")
+		_ = WriteJSON(w, http.StatusOK, itemsPage{Items: []int{1}})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	p := Pagination[itemsPage, int]{
+		Items: func(p *itemsPage) []int { return p.Items },
+		Next:  LinkHeaderPages[itemsPage](),
+	}
+
+	got, err := collect(Paginate(context.Background(), c, srv.URL, p, WithBearerToken("secret")))
+	assert.True(t, errors.Is(err, ErrCrossOriginPage))
+	assert.Equal(t, []int{1}, got)
+
+	p.AllowCrossOrigin = true
+	got, err = collect(Paginate(context.Background(), c, srv.URL, p))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 9}, got)
+
+	_, err = collect(Paginate(context.Background(), c, srv.URL, Pagination[itemsPage, int]{Items: p.Items}))
+	assert.Error(t, err, "Next is required")
+}
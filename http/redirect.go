@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// Errors matched (with errors.Is) when a redirect violates the RedirectOptions. They are
+// not retried by DefaultRetryPredicate.
+var (
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrRedirectBlocked  = errors.New("redirect to another host blocked")
+)
+
+// DefaultMaxRedirects is the default limit on the number of redirects followed, as in net/http
+const DefaultMaxRedirects = 10
+
+// alwaysStripped are the headers removed from cross-origin redirects regardless of the options
+var alwaysStripped = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// RedirectOptions configures how redirects are followed
+//
+// - MaxRedirects is the number of redirects followed before failing with ErrTooManyRedirects (default DefaultMaxRedirects)
+// - NoFollow returns redirect responses as-is, rather than following them
+// - SameHostOnly fails redirects to another host with ErrRedirectBlocked
+// - StripHeaders are removed from redirects to another origin (scheme, host, and port), in
+// addition to Authorization, Proxy-Authorization, and Cookie
+type RedirectOptions struct {
+	MaxRedirects int
+	NoFollow     bool
+	SameHostOnly bool
+	StripHeaders []string
+}
+
+// defaultRedirectPolicy checks the redirects of clients without other settings, so that
+// sensitive headers are always stripped from cross-origin redirects
+var defaultRedirectPolicy = RedirectOptions{}
+
+// IsCrossOriginRedirect reports whether req is a redirect to another origin than the
+// original request. Middleware that adds credentials (like TokenRoundTripper and
+// HMACRoundTripper) must not add them to such requests. It follows the redirect
+// responses that an http.Client links to each hop (and that Request.Clone keeps) back
+// to the original request, so it works for any client.
+func IsCrossOriginRedirect(req *http.Request) bool {
+	original := req
+	for original.Response != nil && original.Response.Request != nil {
+		original = original.Response.Request
+	}
+
+	return original != req && crossOrigin(original.URL, req.URL)
+}
+
+// WithRedirectPolicy overrides the client's redirect settings for this request
+func WithRedirectPolicy(opts RedirectOptions) ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.redirects = &opts
+		})
+
+		return nil
+	}
+}
+
+// checkRedirect is an http.Client CheckRedirect function enforcing o, and recording each
+// hop as an event on the span of the request
+func (o *RedirectOptions) checkRedirect(req *http.Request, via []*http.Request) error {
+	span := telemetry.SpanFromContext(req.Context())
+	original := via[0]
+	hop := len(via)
+
+	attrs := []telemetry.KeyValue{
+		telemetry.KVInt("http.redirect.hop", hop),
+		telemetry.KVString("http.redirect.location", redirectLocation(req.URL)),
+	}
+	if req.Response != nil {
+		attrs = append(attrs, telemetry.KVInt("http.response.status_code", req.Response.StatusCode))
+	}
+
+	if o.NoFollow {
+		span.AddEvent("http.redirect", telemetry.WithAttributes(append(attrs, telemetry.KVBool("http.redirect.followed", false))...))
+		return http.ErrUseLastResponse
+	}
+
+	maxRedirects := o.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	if hop > maxRedirects {
+		span.AddEvent("http.redirect", telemetry.WithAttributes(append(attrs, telemetry.KVBool("http.redirect.followed", false))...))
+		return errors.Wrap(ErrTooManyRedirects, "", "max_redirects", maxRedirects)
+	}
+
+	if o.SameHostOnly && !strings.EqualFold(req.URL.Host, original.URL.Host) {
+		span.AddEvent("http.redirect", telemetry.WithAttributes(append(attrs, telemetry.KVBool("http.redirect.followed", false))...))
+		return errors.Wrap(ErrRedirectBlocked, "", "from_host", original.URL.Host, "to_host", req.URL.Host)
+	}
+
+	if crossOrigin(original.URL, req.URL) {
+		var stripped []string
+		for _, name := range slices.Concat(alwaysStripped, o.StripHeaders) {
+			name = http.CanonicalHeaderKey(name)
+			if _, ok := req.Header[name]; ok {
+				req.Header.Del(name)
+				stripped = append(stripped, name)
+			}
+		}
+
+		attrs = append(attrs, telemetry.KVBool("http.redirect.cross_origin", true))
+		if len(stripped) > 0 {
+			attrs = append(attrs, telemetry.KVStringSlice("http.redirect.stripped_headers", stripped))
+		}
+	}
+
+	span.AddEvent("http.redirect", telemetry.WithAttributes(append(attrs, telemetry.KVBool("http.redirect.followed", true))...))
+
+	return nil
+}
+
+// crossOrigin reports whether the scheme, host, or port of a and b differ
+func crossOrigin(a, b *url.URL) bool {
+	return !strings.EqualFold(a.Scheme, b.Scheme) ||
+		!strings.EqualFold(a.Hostname(), b.Hostname()) ||
+		effectivePort(a) != effectivePort(b)
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	default:
+		return ""
+	}
+}
+
+// redirectLocation is the URL without its query, fragment, or user info, which may be sensitive
+func redirectLocation(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
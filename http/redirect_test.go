@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+func echoHeaders(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte(strings.Join([]string{
+		r.Header.Get("Authorization"),
+		r.Header.Get("X-Secret"),
+		r.Header.Get("X-Keep"),
+	}, ",")))
+}
+
+func TestTelemeterClient_redirectHeaders(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(echoHeaders))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", echoHeaders)
+	mux.HandleFunc("/same", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.SetRedirectPolicy(RedirectOptions{StripHeaders: []string{"x-secret"}})
+
+	headers := WithHeaders(http.Header{
+		"Authorization": {"Bearer token"},
+		"X-Secret":      {"secret"},
+		"X-Keep":        {"keep"},
+	})
+
+	body, _, err := c.GetBody(context.Background(), srv.URL+"/same", headers)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token,secret,keep", string(body), "same-origin redirects keep their headers")
+
+	body, _, err = c.GetBody(context.Background(), srv.URL+"/cross", headers)
+	assert.NoError(t, err)
+	assert.Equal(t, ",,keep", string(body), "cross-origin redirects strip sensitive headers")
+}
+
+func TestTelemeterClient_redirectAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(echoHeaders))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", echoHeaders)
+	mux.HandleFunc("/same", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for name, mw := range map[string]Middleware{
+		"token": func(next http.RoundTripper) http.RoundTripper {
+			return NewTokenRoundTripper(next, StaticTokenSource{AccessToken: "tok"})
+		},
+		"hmac": func(next http.RoundTripper) http.RoundTripper {
+			return NewHMACRoundTripper(next, "k1", []byte("s3cret"))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			zero := 0
+			c, err := NewClientBuilder(logging.NewJSONFileLogger(io.Discard), newTestTelemeter(t)).
+				Use(mw).
+				Retries(RetryOptions{RetryMax: &zero}).
+				Build()
+			assert.NoError(t, err)
+
+			body, _, err := c.GetBody(context.Background(), srv.URL+"/same")
+			assert.NoError(t, err)
+			assert.NotEqual(t, ",,", string(body), "same-origin redirects are authenticated")
+
+			body, _, err = c.GetBody(context.Background(), srv.URL+"/cross")
+			assert.NoError(t, err)
+			assert.Equal(t, ",,", string(body), "cross-origin redirects are not authenticated by middleware")
+		})
+	}
+}
+
+func TestIsCrossOriginRedirect(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(echoHeaders))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", echoHeaders)
+	mux.HandleFunc("/same", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// a plain http.Client, without a RedirectOptions checking its redirects
+	client := &http.Client{Transport: NewTokenRoundTripper(http.DefaultTransport, StaticTokenSource{AccessToken: "tok"})}
+	get := func(path string) string {
+		resp, err := client.Get(srv.URL + path) //nolint:noctx // test
+		assert.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	assert.Equal(t, "Bearer tok,,", get("/same"))
+	assert.Equal(t, ",,", get("/cross"))
+
+	req := httptest.NewRequest(http.MethodGet, srv.URL+"/cross", http.NoBody)
+	assert.False(t, IsCrossOriginRedirect(req), "original requests are not redirects")
+}
+
+func TestTelemeterClient_redirectPolicy(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(echoHeaders))
+	defer other.Close()
+
+	var loops atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", echoHeaders)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		loops.Add(1)
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	retries := 2
+	c.ConfigureRetries(RetryOptions{RetryWaitMin: 1, RetryWaitMax: 1, RetryMax: &retries})
+	c.SetRedirectPolicy(RedirectOptions{MaxRedirects: 3})
+
+	_, _, err := c.GetBody(context.Background(), srv.URL+"/loop")
+	assert.True(t, errors.Is(err, ErrTooManyRedirects))
+	assert.Equal(t, int64(4), loops.Load(), "redirect policy violations are not retried")
+
+	_, _, err = c.GetBody(context.Background(), srv.URL+"/cross", WithRedirectPolicy(RedirectOptions{SameHostOnly: true}))
+	assert.True(t, errors.Is(err, ErrRedirectBlocked))
+
+	loops.Store(0)
+	_, resp, err := c.GetBody(context.Background(), srv.URL+"/loop", WithRedirectPolicy(RedirectOptions{NoFollow: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "/loop", resp.Header.Get("Location"))
+	assert.Equal(t, int64(1), loops.Load())
+}
+
+func TestCrossOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://api.example.com/a", "https://api.example.com/b", false},
+		{"https://api.example.com/a", "https://API.example.com:443/b", false},
+		{"http://api.example.com/a", "https://api.example.com/a", true},
+		{"https://api.example.com/a", "https://api.example.com:8443/a", true},
+		{"https://api.example.com/a", "https://cdn.example.com/a", true},
+	}
+
+	for _, tt := range tests {
+		a, err := url.Parse(tt.a)
+		assert.NoError(t, err)
+		b, err := url.Parse(tt.b)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.want, crossOrigin(a, b), "%s -> %s", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+)
+
+// requestConfig holds per-request settings made by ClientOpts that are consumed by the
+// client itself, rather than being applied directly to the request
+type requestConfig struct {
+	errorDecoders []ErrorDecoder
+	retries       RetryOptions
+	route         string
+	hedge         *HedgeOptions
+	noHedge       bool
+	redirects     *RedirectOptions
+}
+
+type requestConfigKey struct{}
+
+// withRequestConfig returns a context carrying a fresh requestConfig
+func withRequestConfig(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestConfigKey{}, &requestConfig{})
+}
+
+// requestConfigFrom returns the requestConfig in ctx, or nil if there is none
+func requestConfigFrom(ctx context.Context) *requestConfig {
+	cfg, _ := ctx.Value(requestConfigKey{}).(*requestConfig)
+	return cfg
+}
+
+// configureRequest applies fn to the requestConfig carried by req, adding one if necessary
+func configureRequest(req *Request, fn func(cfg *requestConfig)) {
+	cfg := requestConfigFrom(req.Context())
+	if cfg == nil {
+		ctx := withRequestConfig(req.Context())
+		req.Request = req.Request.WithContext(ctx)
+		cfg = requestConfigFrom(ctx)
+	}
+
+	fn(cfg)
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+type (
+	// Backoff computes how long to wait before retry attemptNum (starting at 0)
+	Backoff = retryablehttp.Backoff
+	// RetryPredicate decides whether a request should be retried, given the response or error
+	RetryPredicate = retryablehttp.CheckRetry
+)
+
+// BackoffStrategy creates the Backoff used for a single request, so that strategies
+// may keep state across the attempts of that request
+type BackoffStrategy func() Backoff
+
+var (
+	// DefaultRetryPredicate retries connection errors (except redirect policy violations)
+	// and 429/5xx responses (except 501)
+	DefaultRetryPredicate RetryPredicate = defaultRetryPredicate
+
+	// ExponentialBackoff waits min*2^attempt, capped at max
+	ExponentialBackoff BackoffStrategy = func() Backoff { return exponentialBackoff }
+
+	// ExponentialFullJitterBackoff waits a uniformly random duration between 0 and
+	// min*2^attempt (capped at max)
+	ExponentialFullJitterBackoff BackoffStrategy = func() Backoff { return fullJitterBackoff }
+
+	// DecorrelatedJitterBackoff waits a random duration between min and three times the
+	// previous wait, capped at max
+	DecorrelatedJitterBackoff BackoffStrategy = newDecorrelatedJitterBackoff
+)
+
+func defaultRetryPredicate(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrRedirectBlocked) {
+		return false, err
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+// ConstantBackoff waits d between every attempt, ignoring the min and max wait
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return func() Backoff {
+		return func(_, _ time.Duration, _ int, _ *http.Response) time.Duration {
+			return d
+		}
+	}
+}
+
+// RetryOptions configures retries. In ConfigureRetries, zero values leave the client's
+// current settings in place; in WithRetries, zero values fall back to the client's settings.
+//
+// - Backoff is the strategy for waiting between attempts (default ExponentialBackoff)
+// - ShouldRetry decides whether to retry (default DefaultRetryPredicate)
+// - IgnoreRetryAfter disables waiting for the Retry-After header of 429 and 503 responses
+type RetryOptions struct {
+	RetryWaitMin time.Duration // Minimum time to wait
+	RetryWaitMax time.Duration // Maximum time to wait
+	RetryMax     *int          // Maximum number of retries
+
+	Backoff          BackoffStrategy
+	ShouldRetry      RetryPredicate
+	IgnoreRetryAfter bool
+}
+
+// merge returns o with any settings present in override replacing its own
+func (o RetryOptions) merge(override RetryOptions) RetryOptions {
+	if override.RetryWaitMin > 0 {
+		o.RetryWaitMin = override.RetryWaitMin
+	}
+
+	if override.RetryWaitMax > 0 {
+		o.RetryWaitMax = override.RetryWaitMax
+	}
+
+	if override.RetryMax != nil && *override.RetryMax >= 0 {
+		retryMax := *override.RetryMax
+		o.RetryMax = &retryMax
+	}
+
+	if override.Backoff != nil {
+		o.Backoff = override.Backoff
+	}
+
+	if override.ShouldRetry != nil {
+		o.ShouldRetry = override.ShouldRetry
+	}
+
+	if override.IgnoreRetryAfter {
+		o.IgnoreRetryAfter = true
+	}
+
+	return o
+}
+
+// backoff creates the Backoff for a single request
+func (o RetryOptions) backoff() Backoff {
+	strategy := o.Backoff
+	if strategy == nil {
+		strategy = ExponentialBackoff
+	}
+
+	b := strategy()
+	if o.IgnoreRetryAfter {
+		return b
+	}
+
+	return func(minWait, maxWait time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		if wait, ok := retryAfter(resp); ok {
+			return wait
+		}
+
+		return b(minWait, maxWait, attemptNum, resp)
+	}
+}
+
+// WithRetries overrides the client's retry settings for this request
+func WithRetries(opts RetryOptions) ClientOpt {
+	return func(req *Request) error {
+		configureRequest(req, func(cfg *requestConfig) {
+			cfg.retries = cfg.retries.merge(opts)
+		})
+
+		return nil
+	}
+}
+
+// WithRetryMax overrides the client's maximum number of retries for this request
+func WithRetryMax(retryMax int) ClientOpt {
+	return WithRetries(RetryOptions{RetryMax: &retryMax})
+}
+
+// WithBackoff overrides the client's backoff strategy for this request
+func WithBackoff(strategy BackoffStrategy) ClientOpt {
+	return WithRetries(RetryOptions{Backoff: strategy})
+}
+
+// WithRetryPredicate overrides the client's retry predicate for this request
+func WithRetryPredicate(shouldRetry RetryPredicate) ClientOpt {
+	return WithRetries(RetryOptions{ShouldRetry: shouldRetry})
+}
+
+func exponentialBackoff(minWait, maxWait time.Duration, attemptNum int, _ *http.Response) time.Duration {
+	return exponentialWait(minWait, maxWait, attemptNum)
+}
+
+func fullJitterBackoff(minWait, maxWait time.Duration, attemptNum int, _ *http.Response) time.Duration {
+	ceiling := exponentialWait(minWait, maxWait, attemptNum)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling) + 1)) //nolint:gosec // jitter does not need a secure source
+}
+
+func newDecorrelatedJitterBackoff() Backoff {
+	var prev time.Duration
+
+	return func(minWait, maxWait time.Duration, _ int, _ *http.Response) time.Duration {
+		if prev < minWait {
+			prev = minWait
+		}
+
+		upper := 3 * prev
+		if upper <= minWait {
+			prev = minWait
+			return prev
+		}
+
+		wait := minWait + time.Duration(rand.Int64N(int64(upper-minWait)+1)) //nolint:gosec // jitter does not need a secure source
+		if maxWait > 0 && wait > maxWait {
+			wait = maxWait
+		}
+
+		prev = wait
+
+		return wait
+	}
+}
+
+func exponentialWait(minWait, maxWait time.Duration, attemptNum int) time.Duration {
+	mult := math.Pow(2, float64(attemptNum)) * float64(minWait)
+	wait := time.Duration(mult)
+	if float64(wait) != mult || wait > maxWait {
+		wait = maxWait
+	}
+
+	return wait
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, in either
+// delay-seconds or HTTP-date form
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	at, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(at), 0), true
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+	t.Parallel()
+
+	minWait, maxWait := 10*time.Millisecond, 100*time.Millisecond
+
+	exp := ExponentialBackoff()
+	assert.Equal(t, 10*time.Millisecond, exp(minWait, maxWait, 0, nil))
+	assert.Equal(t, 40*time.Millisecond, exp(minWait, maxWait, 2, nil))
+	assert.Equal(t, maxWait, exp(minWait, maxWait, 10, nil))
+	assert.Equal(t, maxWait, exp(minWait, maxWait, 1000, nil))
+
+	full := ExponentialFullJitterBackoff()
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := full(minWait, maxWait, attempt, nil)
+		assert.GreaterOrEqual(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, exponentialWait(minWait, maxWait, attempt))
+	}
+
+	decorrelated := DecorrelatedJitterBackoff()
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := decorrelated(minWait, maxWait, attempt, nil)
+		assert.GreaterOrEqual(t, wait, minWait)
+		assert.LessOrEqual(t, wait, maxWait)
+	}
+
+	constant := ConstantBackoff(5 * time.Millisecond)()
+	assert.Equal(t, 5*time.Millisecond, constant(minWait, maxWait, 3, nil))
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	resp := func(status int, header string) *http.Response {
+		r := &http.Response{StatusCode: status, Header: http.Header{}}
+		if header != "" {
+			r.Header.Set("Retry-After", header)
+		}
+		return r
+	}
+
+	wait, ok := retryAfter(resp(http.StatusTooManyRequests, "3"))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = retryAfter(resp(http.StatusServiceUnavailable, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)))
+	assert.True(t, ok)
+	assert.Greater(t, wait, 58*time.Minute)
+
+	_, ok = retryAfter(resp(http.StatusInternalServerError, "3"))
+	assert.False(t, ok)
+
+	_, ok = retryAfter(resp(http.StatusTooManyRequests, "soon"))
+	assert.False(t, ok)
+
+	_, ok = retryAfter(nil)
+	assert.False(t, ok)
+
+	withHeader := RetryOptions{Backoff: ConstantBackoff(time.Millisecond)}.backoff()
+	assert.Equal(t, 3*time.Second, withHeader(0, 0, 0, resp(http.StatusTooManyRequests, "3")))
+
+	ignored := RetryOptions{Backoff: ConstantBackoff(time.Millisecond), IgnoreRetryAfter: true}.backoff()
+	assert.Equal(t, time.Millisecond, ignored(0, 0, 0, resp(http.StatusTooManyRequests, "3")))
+}
+
+func TestTelemeterClient_perRequestRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	_, _, err := c.GetBody(ctx, srv.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), calls.Swap(0), "client default allows no retries")
+
+	_, _, err = c.GetBody(ctx, srv.URL, WithRetryMax(2), WithBackoff(ConstantBackoff(time.Millisecond)))
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), calls.Swap(0))
+
+	_, _, err = c.GetBody(ctx, srv.URL, WithRetryMax(2), WithBackoff(ConstantBackoff(time.Millisecond)),
+		WithRetryPredicate(func(context.Context, *http.Response, error) (bool, error) { return false, nil }))
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), calls.Swap(0))
+
+	_, _, err = c.GetBody(ctx, srv.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), calls.Swap(0), "per-request settings do not leak into the client")
+}
+
+func TestTelemeterClient_concurrentRetryConfig(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			n := i
+			c.ConfigureRetries(RetryOptions{RetryMax: &n})
+		}()
+
+		go func() {
+			defer wg.Done()
+			_, _, err := c.GetBody(context.Background(), srv.URL, WithRetryMax(i))
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+}
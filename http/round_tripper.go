@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
@@ -16,6 +17,7 @@ type TelemeterRoundTripper struct {
 	base http.RoundTripper
 
 	spanOpts []telemetry.StartSpanOption
+	metrics  *clientMetrics
 }
 
 var _ http.RoundTripper = (*TelemeterRoundTripper)(nil)
@@ -27,6 +29,7 @@ func NewTelemeterRoundTripper(base http.RoundTripper, tel *telemetry.Telemeter,
 		base:     propagateRT,
 		tel:      tel,
 		spanOpts: opts,
+		metrics:  newClientMetrics(tel),
 	}
 }
 
@@ -34,7 +37,20 @@ func (rt *TelemeterRoundTripper) RoundTrip(req *http.Request) (resp *http.Respon
 	ctx, span := rt.tel.StartSpan(req.Context(), "http", "RoundTrip", rt.spanOpts...)
 	defer span.End()
 
+	attrs := requestMetricAttributes(req)
+	if cfg := requestConfigFrom(ctx); cfg != nil && cfg.route != "" {
+		span.SetAttributes(telemetry.KVString("http.route", cfg.route))
+	}
+
+	start := time.Now()
+	rt.metrics.active.Add(ctx, 1, telemetry.WithMetricAttributes(attrs...))
+
 	defer func() {
+		rt.metrics.active.Add(ctx, -1, telemetry.WithMetricAttributes(attrs...))
+		rt.metrics.duration.Record(ctx, time.Since(start).Seconds(), telemetry.WithMetricAttributes(
+			append(attrs, telemetry.KVString("http.response.status_class", statusClass(resp, err)))...,
+		))
+
 		if resp != nil {
 			span.SetAttributes(telemetry.HTTPAttributesFromHTTPStatusCode(resp)...)
 		}
@@ -47,5 +63,5 @@ func (rt *TelemeterRoundTripper) RoundTrip(req *http.Request) (resp *http.Respon
 		}
 	}()
 
-	return rt.base.RoundTrip(req.WithContext(ctx))
+	return rt.base.RoundTrip(req.WithContext(withClientTrace(ctx, span)))
 }
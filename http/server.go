@@ -0,0 +1,262 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/logging/level"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// ServerOptions configures a Server
+//
+// - Addr is the address to listen on (see http.Server)
+// - Name names the server in traces (default "http.server")
+// - ReadHeaderTimeout (default 5s), ReadTimeout, WriteTimeout, and IdleTimeout are passed to the http.Server
+// - HandlerTimeout, if set, is the deadline on the context of each request (see TimeoutMiddleware)
+// - MaxBodyBytes, if set, limits the size of request bodies (see MaxBodyMiddleware)
+// - ShutdownTimeout is how long in-flight requests have to finish on shutdown (default 10s)
+// - LivenessPath (default "/livez") and ReadinessPath (default "/readyz") are the health
+// endpoints, which are served without the middleware
+// - Middleware is added inside the standard middleware, the first being the outermost
+type ServerOptions struct {
+	Addr              string
+	Name              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	HandlerTimeout    time.Duration
+	MaxBodyBytes      int64
+	ShutdownTimeout   time.Duration
+	LivenessPath      string
+	ReadinessPath     string
+	Middleware        []ServerMiddleware
+}
+
+func (o *ServerOptions) withDefaults() ServerOptions {
+	opts := *o
+
+	if opts.Name == "" {
+		opts.Name = "http.server"
+	}
+
+	if opts.ReadHeaderTimeout <= 0 {
+		opts.ReadHeaderTimeout = 5 * time.Second
+	}
+
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = 10 * time.Second
+	}
+
+	if opts.LivenessPath == "" {
+		opts.LivenessPath = "/livez"
+	}
+
+	if opts.ReadinessPath == "" {
+		opts.ReadinessPath = "/readyz"
+	}
+
+	return opts
+}
+
+// ReadinessCheck reports an error if the server is not ready to receive traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// Server is an http.Server with the standard middleware (telemetry.Handler, request IDs,
+// access logging, panic recovery, timeouts, and body limits), health endpoints, and
+// graceful shutdown
+type Server struct {
+	srv    *http.Server
+	opts   ServerOptions
+	logger logging.Logger
+
+	ready    atomic.Bool
+	checksMu sync.RWMutex
+	checks   map[string]ReadinessCheck
+}
+
+// NewServer creates a Server for handler. If tel is nil, requests are not traced.
+func NewServer(logger logging.Logger, tel *telemetry.Telemeter, handler http.Handler, opts ServerOptions) *Server {
+	opts = opts.withDefaults()
+
+	s := &Server{
+		opts:   opts,
+		logger: logger,
+		checks: map[string]ReadinessCheck{},
+	}
+
+	middleware := []ServerMiddleware{
+		RequestIDMiddleware(logger),
+		AccessLogMiddleware(logger),
+		RecoveryMiddleware(logger),
+	}
+
+	if opts.HandlerTimeout > 0 {
+		middleware = append(middleware, TimeoutMiddleware(opts.HandlerTimeout))
+	}
+
+	if opts.MaxBodyBytes > 0 {
+		middleware = append(middleware, MaxBodyMiddleware(opts.MaxBodyBytes))
+	}
+
+	h := ChainMiddleware(handler, append(middleware, opts.Middleware...)...)
+	if tel != nil {
+		h = telemetry.Handler(h, opts.Name, tel)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(opts.LivenessPath, s.serveLiveness)
+	mux.HandleFunc(opts.ReadinessPath, s.serveReadiness)
+	mux.Handle("/", h)
+
+	s.srv = &http.Server{
+		Addr:              opts.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		ErrorLog:          logging.NewStdLogger(level.Error(logger)),
+	}
+
+	return s
+}
+
+// AddReadinessCheck adds (or replaces) a named check run by the readiness endpoint
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.checksMu.Lock()
+	defer s.checksMu.Unlock()
+
+	s.checks[name] = check
+}
+
+// SetReady overrides whether the server reports itself ready. The server becomes ready when
+// it starts serving, and stops being ready when it starts shutting down.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Handler is the complete handler of the server, including the health endpoints
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
+func (s *Server) serveLiveness(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+func (s *Server) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, "not ready\n")
+		return
+	}
+
+	s.checksMu.RLock()
+	checks := make(map[string]ReadinessCheck, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+	s.checksMu.RUnlock()
+
+	var failures []string
+	for name, check := range checks {
+		if err := check(r.Context()); err != nil {
+			failures = append(failures, name+": "+err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, strings.Join(failures, "\n")+"\n")
+		return
+	}
+
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// Run listens on the configured address and serves until ctx is canceled or an interrupt
+// (SIGINT or SIGTERM, by default) is received, then shuts down gracefully
+func (s *Server) Run(ctx context.Context, interrupt chan os.Signal) error {
+	var lc net.ListenConfig
+
+	ln, err := lc.Listen(ctx, "tcp", s.srv.Addr)
+	if err != nil {
+		return errors.Wrap(err, "could not listen", "addr", s.srv.Addr)
+	}
+
+	return s.Serve(ctx, ln, interrupt)
+}
+
+// Serve is Run with the provided listener
+func (s *Server) Serve(ctx context.Context, ln net.Listener, interrupt chan os.Signal) error {
+	if interrupt == nil {
+		interrupt = make(chan os.Signal, 3)
+		defer close(interrupt)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupt)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	// watches for interrupts
+	g.Go(func() error {
+		select {
+		case <-interrupt:
+			level.Info(s.logger).Message("interrupt received, shutting down")
+			cancel()
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	})
+
+	// runs the server
+	g.Go(func() error {
+		s.ready.Store(true)
+		level.Info(s.logger).Message("server listening", "addr", ln.Addr().String())
+
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.Wrap(err, "server failed")
+		}
+
+		return nil
+	})
+
+	// shuts the server down when necessary
+	g.Go(func() error {
+		<-ctx.Done()
+		s.ready.Store(false)
+
+		shutdownCtx, cncl := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+		defer cncl()
+
+		if err := s.srv.Shutdown(shutdownCtx); err != nil { //nolint:contextcheck // want to use a fresh one here
+			return errors.Wrap(err, "could not shut down gracefully")
+		}
+
+		return nil
+	})
+
+	return g.Wait()
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/logging/level"
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+// HeaderRequestID carries the request ID of a server request, both in the request (if the
+// caller provides one) and the response
+const HeaderRequestID = "X-Request-Id"
+
+// maxRequestIDLength bounds the incoming request IDs that are accepted
+const maxRequestIDLength = 128
+
+// ServerMiddleware wraps an http.Handler to add behavior to every request
+type ServerMiddleware func(next http.Handler) http.Handler
+
+// ChainMiddleware wraps h with the middleware, the first being the outermost
+func ChainMiddleware(h http.Handler, middleware ...ServerMiddleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+
+	return h
+}
+
+// RequestIDMiddleware puts a request ID into the request context (see request.GetRequestID)
+// and the response headers, along with a logger including it (see logging.FromContext). The
+// request ID is taken from the HeaderRequestID request header if it is present and
+// reasonable, and generated otherwise.
+func RequestIDMiddleware(logger logging.Logger) ServerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rid := r.Header.Get(HeaderRequestID)
+			if !validRequestID(rid) {
+				rid = request.GenerateRequestID()
+			}
+
+			w.Header().Set(HeaderRequestID, rid)
+
+			r = r.WithContext(request.NewRequestContextWithRequestID(r.Context(), rid))
+			r, _ = logging.WithRequestInto(r, logger)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func validRequestID(rid string) bool {
+	if rid == "" || len(rid) > maxRequestIDLength {
+		return false
+	}
+
+	for i := range len(rid) {
+		if rid[i] < 0x21 || rid[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AccessLogMiddleware logs a line for each request once it completes, with the status,
+// response size, and duration, using the logger from the request context if there is one
+func AccessLogMiddleware(logger logging.Logger) ServerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := newStatusRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			l := logger
+			if _, ok := request.GetRequestID(r.Context()); ok {
+				l = logging.FromContext(r.Context())
+			}
+
+			level.Info(l).Message("request complete",
+				"status", rec.status(),
+				"response_bytes", rec.bytes,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+		})
+	}
+}
+
+// RecoveryMiddleware recovers from panics in handlers, logging them with a stack trace and
+// responding with a 500 if nothing has been written yet. http.ErrAbortHandler is re-panicked.
+func RecoveryMiddleware(logger logging.Logger) ServerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := newStatusRecorder(w)
+
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+
+				if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(p)
+				}
+
+				l := logging.WithRequest(r, logger)
+				level.Error(l).Err("panic in handler", errors.New(fmt.Sprint(p)), "stack", string(debug.Stack()))
+
+				if !rec.wroteHeader {
+					http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// TimeoutMiddleware sets a deadline of d on the request context. Unlike http.TimeoutHandler,
+// the response is not buffered (so streaming works), and handlers must respect the context.
+func TimeoutMiddleware(d time.Duration) ServerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// MaxBodyMiddleware limits request bodies to n bytes; reading past that fails with an
+// *http.MaxBytesError
+func MaxBodyMiddleware(n int64) ServerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// statusRecorder records the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	bytes       int64
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w}
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader && code >= 200 {
+		r.code = code
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+func (r *statusRecorder) status() int {
+	if !r.wroteHeader {
+		return http.StatusOK
+	}
+
+	return r.code
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestServer_middleware(t *testing.T) {
+	t.Parallel()
+
+	var logs syncBuffer
+	logger := logging.NewJSONFileLogger(&logs)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/id", func(w http.ResponseWriter, r *http.Request) {
+		rid, _ := request.GetRequestID(r.Context())
+		_, hasDeadline := r.Context().Deadline()
+		assert.True(t, hasDeadline)
+
+		_, _ = io.WriteString(w, rid)
+	})
+	mux.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	s := NewServer(logger, newTestTelemeter(t), mux, ServerOptions{
+		HandlerTimeout: time.Second,
+		MaxBodyBytes:   4,
+	})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	do := func(method, path, rid, body string) (*http.Response, string) {
+		req, err := http.NewRequestWithContext(context.Background(), method, srv.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		if rid != "" {
+			req.Header.Set(HeaderRequestID, rid)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck // test
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return resp, string(b)
+	}
+
+	resp, body := do(http.MethodGet, "/id", "", "")
+	assert.NotEmpty(t, body)
+	assert.Equal(t, body, resp.Header.Get(HeaderRequestID))
+
+	resp, body = do(http.MethodGet, "/id", "caller-id", "")
+	assert.Equal(t, "caller-id", body)
+	assert.Equal(t, "caller-id", resp.Header.Get(HeaderRequestID))
+
+	resp, _ = do(http.MethodGet, "/id", strings.Repeat("x", 200), "")
+	assert.NotEqual(t, strings.Repeat("x", 200), resp.Header.Get(HeaderRequestID), "unreasonable IDs are replaced")
+
+	resp, _ = do(http.MethodGet, "/panic", "panic-id", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, _ = do(http.MethodPost, "/upload", "", "too large")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	out := logs.String()
+	assert.Contains(t, out, `"request_id":"caller-id"`)
+	assert.Contains(t, out, "panic in handler")
+	assert.Contains(t, out, `"status":500`)
+	assert.Contains(t, out, `"status":413`)
+}
+
+func TestServer_Serve(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(logging.NewJSONFileLogger(io.Discard), nil, http.NotFoundHandler(), ServerOptions{})
+
+	get := func(h http.Handler, path string) (int, string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec.Code, rec.Body.String()
+	}
+
+	code, _ := get(s.Handler(), "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = get(s.Handler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "not ready until serving")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln, make(chan os.Signal)) }()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/readyz") //nolint:noctx // test
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	s.AddReadinessCheck("db", func(context.Context) error { return errors.New("db unavailable") })
+	code, body := get(s.Handler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "db: db unavailable")
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	code, _ = get(s.Handler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "not ready after shutdown")
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/deferutil"
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+const ContentTypeEventStream = "text/event-stream"
+
+// StreamingClient is a Client that can also stream responses
+//
+//counterfeiter:generate . StreamingClient
+type StreamingClient interface {
+	Client
+
+	Stream(ctx context.Context, method, reqURL string, opts ...ClientOpt) (*StreamResponse, error)
+}
+
+var _ StreamingClient = (*TelemeterClient)(nil)
+
+// StreamResponse is a successful response whose body has not been read. Reading from it
+// reads the body, and closing it closes the body and ends the request span. It must be
+// closed; as a safeguard, an unreachable StreamResponse that was never closed is closed
+// when it is garbage collected.
+type StreamResponse struct {
+	*http.Response
+
+	span     telemetry.Span
+	cleanup  runtime.Cleanup
+	once     sync.Once
+	closeErr error
+}
+
+var _ io.ReadCloser = (*StreamResponse)(nil)
+
+func newStreamResponse(resp *http.Response, span telemetry.Span) *StreamResponse {
+	s := &StreamResponse{
+		Response: resp,
+		span:     span,
+	}
+
+	s.cleanup = runtime.AddCleanup(s, func(body io.Closer) {
+		_ = body.Close()
+		span.End()
+	}, io.Closer(resp.Body))
+
+	return s
+}
+
+func (s *StreamResponse) Read(p []byte) (int, error) {
+	return s.Body.Read(p)
+}
+
+// Close closes the response body and ends the request span. It is safe to call more than once.
+func (s *StreamResponse) Close() error {
+	s.once.Do(func() {
+		s.cleanup.Stop()
+		s.closeErr = s.Body.Close()
+		s.span.End()
+	})
+
+	return s.closeErr
+}
+
+// Stream sends the request and returns the response without reading its body. If the
+// response has a 4xx/5xx status, its body is read, closed, and decoded into an
+// *HTTPResponseError instead.
+func (c *TelemeterClient) Stream(ctx context.Context, method, reqURL string, opts ...ClientOpt) (*StreamResponse, error) {
+	httpResp, span, err := c.sendRequest(ctx, method, reqURL, opts)
+	if err != nil {
+		span.End()
+		return nil, errors.Wrap(err, "could not sendRequest")
+	}
+
+	if httpResp.StatusCode < 400 {
+		return newStreamResponse(httpResp, span), nil
+	}
+
+	defer span.End()
+
+	logger := logging.WithContext(ctx, c.logger)
+	logger = logging.WithClientRequest(logger, httpResp.Request)
+	defer deferutil.CheckDeferLog(logger, httpResp.Body.Close)
+
+	body, errBody := io.ReadAll(httpResp.Body)
+	if errBody != nil {
+		logger.Err("could not read response body", errBody)
+	}
+
+	return nil, c.responseError(httpResp, body)
+}
+
+// NDJSON iterates over the newline-delimited JSON values in body, closing it once the
+// iteration ends. Blank lines are skipped. Iteration stops after the first error.
+func NDJSON[T any](body io.ReadCloser) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer func() { _ = body.Close() }()
+
+		r := bufio.NewReader(body)
+		for lineNum := 1; ; lineNum++ {
+			line, readErr := r.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				var zero T
+				yield(zero, errors.Wrap(readErr, "could not read line", "line", lineNum))
+				return
+			}
+
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				var v T
+				if err := json.Unmarshal(line, &v); err != nil {
+					yield(v, errors.Wrap(err, "could not unmarshal line", "line", lineNum))
+					return
+				}
+
+				if !yield(v, nil) {
+					return
+				}
+			}
+
+			if readErr == io.EOF {
+				return
+			}
+		}
+	}
+}
+
+// Event is a single Server-Sent Event
+type Event struct {
+	// ID is the last event ID seen in the stream, which persists across events
+	ID    string
+	Event string
+	Data  string
+	// Retry is the last reconnection time requested by the server, if any
+	Retry time.Duration
+}
+
+// SSE iterates over the Server-Sent Events in body, closing it once the iteration ends.
+// Comments are skipped, and events without data are not dispatched, following the
+// event stream interpretation rules. Iteration stops after the first error.
+func SSE(body io.ReadCloser) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		defer func() { _ = body.Close() }()
+
+		var (
+			lastID string
+			retry  time.Duration
+			ev     Event
+			data   strings.Builder
+			dirty  bool
+		)
+
+		dispatch := func() bool {
+			defer func() {
+				ev = Event{}
+				data.Reset()
+				dirty = false
+			}()
+
+			if !dirty {
+				return true
+			}
+
+			ev.ID = lastID
+			ev.Retry = retry
+			ev.Data = strings.TrimSuffix(data.String(), "\n")
+			if ev.Event == "" {
+				ev.Event = "message"
+			}
+
+			return yield(ev, nil)
+		}
+
+		r := bufio.NewReader(body)
+		for {
+			line, readErr := r.ReadString('\n')
+			if readErr != nil && readErr != io.EOF {
+				yield(Event{}, errors.Wrap(readErr, "could not read event stream"))
+				return
+			}
+
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+			switch {
+			case line == "":
+				if readErr == nil && !dispatch() {
+					return
+				}
+			case strings.HasPrefix(line, ":"):
+			default:
+				field, value, _ := strings.Cut(line, ":")
+				value = strings.TrimPrefix(value, " ")
+
+				switch field {
+				case "event":
+					ev.Event = value
+				case "data":
+					data.WriteString(value)
+					data.WriteByte('\n')
+					dirty = true
+				case "id":
+					if !strings.ContainsRune(value, 0) {
+						lastID = value
+					}
+				case "retry":
+					if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+						retry = time.Duration(ms) * time.Millisecond
+					}
+				}
+			}
+
+			if readErr == io.EOF {
+				return // an incomplete event at the end of the stream is discarded
+			}
+		}
+	}
+}
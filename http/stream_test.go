@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNDJSON(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID int `json:"id"`
+	}
+
+	body := &closeTracker{Reader: strings.NewReader("{\"id\":1}\n\n{\"id\":2}\r\n{\"id\":3}")}
+
+	var ids []int
+	for r, err := range NDJSON[row](body) {
+		assert.NoError(t, err)
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.True(t, body.closed)
+
+	body = &closeTracker{Reader: strings.NewReader("{\"id\":1}\n{\"id\":2}\n")}
+	for r := range NDJSON[row](body) {
+		assert.Equal(t, 1, r.ID)
+		break
+	}
+	assert.True(t, body.closed, "closed when the iteration stops early")
+
+	body = &closeTracker{Reader: strings.NewReader("{\"id\":1}\nnope\n{\"id\":3}\n")}
+	var errs int
+	for _, err := range NDJSON[row](body) {
+		if err != nil {
+			errs++
+			assert.Contains(t, err.Error(), "line=2")
+		}
+	}
+	assert.Equal(t, 1, errs)
+}
+
+func TestSSE(t *testing.T) {
+	t.Parallel()
+
+	stream := strings.Join([]string{
+		": a comment",
+		"retry: 1500",
+		"",
+		"id: 1",
+		"event: update",
+		"data: first",
+		"data:second",
+		"",
+		"data: no id",
+		"",
+		"data: unterminated",
+	}, "\r\n")
+
+	var events []Event
+	for ev, err := range SSE(io.NopCloser(strings.NewReader(stream))) {
+		assert.NoError(t, err)
+		events = append(events, ev)
+	}
+
+	assert.Equal(t, []Event{
+		{ID: "1", Event: "update", Data: "first\nsecond", Retry: 1500 * time.Millisecond},
+		{ID: "1", Event: "message", Data: "no id", Retry: 1500 * time.Millisecond},
+	}, events)
+}
+
+func TestTelemeterClient_Stream(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		for _, d := range []string{"a", "b"} {
+			_, _ = io.WriteString(w, "data: "+d+"\n\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+
+	resp, err := c.Stream(context.Background(), http.MethodGet, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentTypeEventStream, resp.Header.Get("Content-Type"))
+
+	var data []string
+	for ev, err := range SSE(resp) {
+		assert.NoError(t, err)
+		data = append(data, ev.Data)
+	}
+	assert.Equal(t, []string{"a", "b"}, data)
+	assert.NoError(t, resp.Close(), "closing again is a no-op")
+
+	_, err = c.Stream(context.Background(), http.MethodGet, srv.URL+"/missing")
+	assert.True(t, IsNotFound(err))
+}
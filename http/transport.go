@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// TransportOptions configures the transport created by NewTransport. Zero values use the
+// same defaults as go-retryablehttp (and so NewTelemeterClient).
+//
+// - MaxIdleConns (default 100), MaxIdleConnsPerHost (default GOMAXPROCS+1), and MaxConnsPerHost (default unlimited) size the connection pool
+// - IdleConnTimeout is how long idle connections are kept (default 90s)
+// - DialTimeout (default 30s) and KeepAlive (default 30s) configure new connections
+// - TLSHandshakeTimeout limits the TLS handshake (default 10s)
+// - ResponseHeaderTimeout limits the wait for the first byte of the response headers after
+// the request is written (default unlimited)
+// - TLS configures server verification and client certificates
+// - DisableHTTP2 keeps connections on HTTP/1.1
+type TransportOptions struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	TLS                   *TLSOptions
+	DisableHTTP2          bool
+}
+
+// TLSOptions configures TLS for NewTransport
+//
+// - RootCAs replaces the system roots used to verify servers, and CAFiles (PEM) are added to
+// RootCAs (or the system roots)
+// - Certificates, and the key pair in CertFile and KeyFile (PEM), are presented to servers
+// that request client certificates
+// - MinVersion is the minimum TLS version (default TLS 1.2)
+// - ServerName overrides the name used to verify servers
+type TLSOptions struct {
+	RootCAs      *x509.CertPool
+	CAFiles      []string
+	Certificates []tls.Certificate
+	CertFile     string
+	KeyFile      string
+	MinVersion   uint16
+	ServerName   string
+}
+
+func (o *TLSOptions) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		RootCAs:      o.RootCAs,
+		Certificates: append([]tls.Certificate(nil), o.Certificates...),
+		MinVersion:   o.MinVersion,
+		ServerName:   o.ServerName,
+	}
+
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if len(o.CAFiles) > 0 {
+		if cfg.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			cfg.RootCAs = pool
+		} else {
+			cfg.RootCAs = cfg.RootCAs.Clone()
+		}
+
+		for _, file := range o.CAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not read CA file", "path", file)
+			}
+
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.WithDetails(errors.New("no certificates found in CA file"), "path", file)
+			}
+		}
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load client certificate", "cert_file", o.CertFile, "key_file", o.KeyFile)
+		}
+
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	return cfg, nil
+}
+
+func defaultDuration(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+
+	return d
+}
+
+// NewTransport creates an *http.Transport with the provided options
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	maxIdle := opts.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = 100
+	}
+
+	maxIdlePerHost := opts.MaxIdleConnsPerHost
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = runtime.GOMAXPROCS(0) + 1
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDuration(opts.DialTimeout, 30*time.Second),
+		KeepAlive: defaultDuration(opts.KeepAlive, 30*time.Second),
+	}
+
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdlePerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       defaultDuration(opts.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   defaultDuration(opts.TLSHandshakeTimeout, 10*time.Second),
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     !opts.DisableHTTP2,
+	}
+
+	if opts.TLS != nil {
+		cfg, err := opts.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = cfg
+	}
+
+	if opts.DisableHTTP2 {
+		// a non-nil, empty map disables the automatic HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return t, nil
+}
+
+// withClientTrace adds an httptrace.ClientTrace to ctx that records span events for the
+// DNS, connect, TLS, and first byte timings of a request attempt
+func withClientTrace(ctx context.Context, span telemetry.Span) context.Context {
+	if !span.IsRecording() {
+		return ctx
+	}
+
+	var (
+		mu       sync.Mutex
+		dnsStart time.Time
+		conStart = map[string]time.Time{}
+		tlsStart time.Time
+		wrote    time.Time
+	)
+
+	since := func(start time.Time) telemetry.KeyValue {
+		return telemetry.KVFloat64("duration_ms", float64(time.Since(start).Microseconds())/1000)
+	}
+
+	event := func(name string, attrs ...telemetry.KeyValue) {
+		span.AddEvent(name, telemetry.WithAttributes(attrs...))
+	}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			event("http.get_conn", telemetry.KVString("net.peer", hostPort))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			event("http.got_conn",
+				telemetry.KVBool("reused", info.Reused),
+				telemetry.KVBool("was_idle", info.WasIdle),
+			)
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			mu.Lock()
+			dnsStart = time.Now()
+			mu.Unlock()
+
+			event("http.dns.start", telemetry.KVString("host", info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			mu.Lock()
+			attrs := []telemetry.KeyValue{since(dnsStart), telemetry.KVInt("addrs", len(info.Addrs))}
+			mu.Unlock()
+
+			if info.Err != nil {
+				attrs = append(attrs, telemetry.KVString("error", info.Err.Error()))
+			}
+			event("http.dns.done", attrs...)
+		},
+		ConnectStart: func(network, addr string) {
+			mu.Lock()
+			conStart[network+addr] = time.Now()
+			mu.Unlock()
+
+			event("http.connect.start", telemetry.KVString("addr", addr))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			mu.Lock()
+			attrs := []telemetry.KeyValue{telemetry.KVString("addr", addr), since(conStart[network+addr])}
+			mu.Unlock()
+
+			if err != nil {
+				attrs = append(attrs, telemetry.KVString("error", err.Error()))
+			}
+			event("http.connect.done", attrs...)
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			tlsStart = time.Now()
+			mu.Unlock()
+
+			event("http.tls.start")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			mu.Lock()
+			attrs := []telemetry.KeyValue{since(tlsStart), telemetry.KVString("tls.version", tls.VersionName(state.Version))}
+			mu.Unlock()
+
+			if state.NegotiatedProtocol != "" {
+				attrs = append(attrs, telemetry.KVString("tls.alpn", state.NegotiatedProtocol))
+			}
+			if err != nil {
+				attrs = append(attrs, telemetry.KVString("error", err.Error()))
+			}
+			event("http.tls.done", attrs...)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			mu.Lock()
+			wrote = time.Now()
+			mu.Unlock()
+
+			if info.Err != nil {
+				event("http.wrote_request", telemetry.KVString("error", info.Err.Error()))
+				return
+			}
+			event("http.wrote_request")
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			attrs := []telemetry.KeyValue{since(wrote)}
+			mu.Unlock()
+
+			event("http.first_byte", attrs...)
+		},
+	})
+}
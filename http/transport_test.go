@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gsmcwhirter/go-util/v12/logging"
+	"github.com/gsmcwhirter/go-util/v12/telemetry"
+)
+
+// keepingExporter is an in-memory exporter that keeps its spans on shutdown
+type keepingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	exp := keepingExporter{tracetest.NewInMemoryExporter()}
+	tel := telemetry.NewTelemeter("test", "v0", "test_instance", exp, nil, 1.0)
+
+	build := func(opts TransportOptions) *TelemeterClient {
+		zero := 0
+		c, err := NewClientBuilder(logging.NewJSONFileLogger(io.Discard), tel).
+			Transport(opts).
+			Retries(RetryOptions{RetryMax: &zero}).
+			Build()
+		assert.NoError(t, err)
+
+		return c
+	}
+
+	body, _, err := build(TransportOptions{TLS: &TLSOptions{CAFiles: []string{caFile}}}).GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	body, _, err = build(TransportOptions{TLS: &TLSOptions{CAFiles: []string{caFile}}, DisableHTTP2: true}).GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(body))
+
+	_, _, err = build(TransportOptions{}).GetBody(context.Background(), srv.URL)
+	assert.Error(t, err, "the test CA is not trusted by default")
+
+	_, _, err = build(TransportOptions{TLS: &TLSOptions{CAFiles: []string{caFile}, MinVersion: tls.VersionTLS13}}).GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+
+	transport, err := NewTransport(TransportOptions{TLS: &TLSOptions{CAFiles: []string{caFile}}})
+	assert.NoError(t, err)
+	body, _, err = NewTelemeterClientWithTransport(logging.NewJSONFileLogger(io.Discard), tel, transport).GetBody(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	_, err = NewClientBuilder(logging.NewJSONFileLogger(io.Discard), tel).
+		Transport(TransportOptions{TLS: &TLSOptions{CAFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}}}).
+		Build()
+	assert.Error(t, err)
+
+	assert.NoError(t, tel.Shutdown(context.Background())) // flushes the spans
+
+	events := map[string]bool{}
+	for _, span := range exp.GetSpans() {
+		if span.Name != "RoundTrip" {
+			continue
+		}
+		for _, e := range span.Events {
+			events[e.Name] = true
+		}
+	}
+
+	for _, name := range []string{"http.get_conn", "http.got_conn", "http.connect.done", "http.tls.done", "http.wrote_request", "http.first_byte"} {
+		assert.True(t, events[name], name)
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+// Empty can be used as the Req type parameter of Do and DoWithError for requests without a body
+type Empty struct{}
+
+// TypedResponseError is returned by DoWithError for 4xx/5xx responses whose body could be
+// decoded into an E
+type TypedResponseError[E any] struct {
+	*HTTPResponseError
+	Detail E
+}
+
+func (e *TypedResponseError[E]) Unwrap() error {
+	return e.HTTPResponseError
+}
+
+// Do issues a request through client, sending body (unless it is nil or Empty) as JSON
+// and decoding a successful JSON response into a Resp
+func Do[Req, Resp any](ctx context.Context, client Client, method, reqURL string, body Req, opts ...ClientOpt) (Resp, *http.Response, error) {
+	var resp Resp
+
+	if !isEmptyBody(body) {
+		opts = append(opts[:len(opts):len(opts)], WithJSONBody(body))
+	}
+
+	httpResp, err := client.RequestJSON(ctx, &resp, method, reqURL, opts...)
+
+	return resp, httpResp, err
+}
+
+// DoWithError is Do, additionally decoding the body of a 4xx/5xx response into an E,
+// returned as the Detail of a *TypedResponseError[E]. If the body cannot be decoded, the
+// *HTTPResponseError is returned as-is.
+func DoWithError[Req, Resp, E any](ctx context.Context, client Client, method, reqURL string, body Req, opts ...ClientOpt) (Resp, *http.Response, error) {
+	resp, httpResp, err := Do[Req, Resp](ctx, client, method, reqURL, body, opts...)
+	if err == nil {
+		return resp, httpResp, nil
+	}
+
+	var respErr *HTTPResponseError
+	if !errors.As(err, &respErr) || len(respErr.Body) == 0 {
+		return resp, httpResp, err
+	}
+
+	var detail E
+	if json.Unmarshal(respErr.Body, &detail) != nil {
+		return resp, httpResp, err
+	}
+
+	return resp, httpResp, &TypedResponseError[E]{
+		HTTPResponseError: respErr,
+		Detail:            detail,
+	}
+}
+
+func isEmptyBody(body interface{}) bool {
+	switch body.(type) {
+	case nil, Empty, *Empty:
+		return true
+	}
+
+	v := reflect.ValueOf(body)
+	switch v.Kind() { //nolint:exhaustive // only nillable kinds matter
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
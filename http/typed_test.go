@@ -0,0 +1,65 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	uhttp "github.com/gsmcwhirter/go-util/v12/http"
+	"github.com/gsmcwhirter/go-util/v12/http/httpfakes"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+type createUser struct {
+	Name string `json:"name"`
+}
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type apiError struct {
+	Code string `json:"code"`
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	fake := &httpfakes.FakeClient{}
+	fake.RequestJSONStub = func(_ context.Context, target interface{}, method, reqURL string, opts ...uhttp.ClientOpt) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, method)
+		assert.Equal(t, "http://example.com/users", reqURL)
+		assert.Len(t, opts, 1)
+
+		return &http.Response{StatusCode: http.StatusCreated}, json.Unmarshal([]byte(`{"id":1,"name":"alice"}`), target)
+	}
+
+	got, resp, err := uhttp.Do[createUser, user](context.Background(), fake, http.MethodPost, "http://example.com/users", createUser{Name: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, user{ID: 1, Name: "alice"}, got)
+}
+
+func TestDoWithError(t *testing.T) {
+	t.Parallel()
+
+	fake := &httpfakes.FakeClient{}
+	fake.RequestJSONReturns(&http.Response{StatusCode: http.StatusConflict}, &uhttp.HTTPResponseError{
+		Body: []byte(`{"code":"exists"}`),
+	})
+
+	_, _, err := uhttp.DoWithError[uhttp.Empty, user, apiError](context.Background(), fake, http.MethodGet, "http://example.com/users/1", uhttp.Empty{})
+
+	var typed *uhttp.TypedResponseError[apiError]
+	assert.ErrorAs(t, err, &typed)
+	assert.Equal(t, apiError{Code: "exists"}, typed.Detail)
+
+	var respErr *uhttp.HTTPResponseError
+	assert.ErrorAs(t, err, &respErr)
+
+	_, _, _, _, opts := fake.RequestJSONArgsForCall(0)
+	assert.Empty(t, opts, "Empty should not send a body")
+}
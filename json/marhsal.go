@@ -38,6 +38,11 @@ func MarshalToBuffer(i interface{}) ([]byte, error) {
 	return d, nil
 }
 
+// MarshalIndent is like Marshal, but with each element on its own line, indented
+func MarshalIndent(i interface{}, prefix, indent string) ([]byte, error) {
+	return sj.MarshalIndent(i, prefix, indent)
+}
+
 func ProtoMarshalAppend(buf []byte, i proto.Message) ([]byte, error) {
 	return ProtoMarshalAppendOpts(buf, i, protojson.MarshalOptions{})
 }
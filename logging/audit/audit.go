@@ -0,0 +1,225 @@
+// Package audit provides an append-only audit trail on top of logging.Logger. Each
+// record carries a monotonically increasing sequence number and a hash linking it
+// to the previous record, so that Verify can detect records that were removed,
+// reordered, or modified.
+//
+// Records must be written through a JSON logger without key normalization (e.g.,
+// logging.NewJSONFileLogger), and the audit trail should not share a destination
+// with operational logs. Keys other than the record keys (e.g., from logging.With)
+// are written but not covered by the hash chain.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+// Record keys written for every audit record
+const (
+	KeySeq      = "seq"
+	KeyTime     = "ts"
+	KeyEvent    = "event"
+	KeyData     = "data"
+	KeyPrevHash = "prev_hash"
+	KeyHash     = "hash"
+)
+
+// Head identifies the last record of an audit trail. The zero value is the head of an
+// empty trail.
+type Head struct {
+	Seq  uint64
+	Hash string
+}
+
+// Logger writes hash-chained audit records. It is safe for concurrent use.
+type Logger struct {
+	mu   sync.Mutex
+	out  logging.Logger
+	head Head
+	now  func() time.Time
+}
+
+// Opt configures a Logger
+type Opt func(*Logger)
+
+// Resume continues the chain after an existing head (see Verify and OpenFile)
+func Resume(head Head) Opt {
+	return func(a *Logger) {
+		a.head = head
+	}
+}
+
+// WithClock overrides the source of record timestamps
+func WithClock(now func() time.Time) Opt {
+	return func(a *Logger) {
+		a.now = now
+	}
+}
+
+// New creates an audit Logger writing records through out
+func New(out logging.Logger, opts ...Opt) *Logger {
+	a := &Logger{
+		out: out,
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Head returns the head of the chain as of the last successfully written record
+func (a *Logger) Head() Head {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.head
+}
+
+// Record writes an audit record for event with the provided key/value pairs as its data.
+// The chain only advances if the record was written successfully.
+func (a *Logger) Record(event string, keyvals ...interface{}) error {
+	data, err := encodeData(keyvals)
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit data", "event", event)
+	}
+
+	eventRaw, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit event", "event", event)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seq := a.head.Seq + 1
+	seqRaw := []byte(fmt.Sprintf("%d", seq))
+
+	tsRaw, err := json.Marshal(a.now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit timestamp")
+	}
+
+	prevRaw, err := json.Marshal(a.head.Hash)
+	if err != nil {
+		return errors.Wrap(err, "could not encode previous hash")
+	}
+
+	hash := chainHash(seqRaw, tsRaw, eventRaw, data, prevRaw)
+	hashRaw, err := json.Marshal(hash)
+	if err != nil {
+		return errors.Wrap(err, "could not encode hash")
+	}
+
+	if logErr := a.out.Log(
+		KeySeq, json.RawMessage(seqRaw),
+		KeyTime, json.RawMessage(tsRaw),
+		KeyEvent, json.RawMessage(eventRaw),
+		KeyData, json.RawMessage(data),
+		KeyPrevHash, json.RawMessage(prevRaw),
+		KeyHash, json.RawMessage(hashRaw),
+	); logErr != nil {
+		return errors.Wrap(logErr, "could not write audit record", "seq", seq)
+	}
+
+	a.head = Head{Seq: seq, Hash: hash}
+
+	return nil
+}
+
+// chainHash is the hex sha256 of the raw JSON values of a record, in a fixed order
+// and newline separated (compact JSON never contains a raw newline)
+func chainHash(seq, ts, event, data, prev []byte) string {
+	h := sha256.New()
+	for i, part := range [][]byte{seq, ts, event, data, prev} {
+		if i > 0 {
+			h.Write([]byte{'\n'})
+		}
+		h.Write(part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// encodeData builds a compact JSON object with sorted keys from keyvals, with the
+// last value winning for duplicate keys
+func encodeData(keyvals []interface{}) ([]byte, error) {
+	vals := make(map[string]interface{}, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+
+		var val interface{}
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+
+		if err, ok := val.(error); ok {
+			if _, isMarshaler := val.(json.Marshaler); !isMarshaler {
+				val = err.Error()
+			}
+		}
+
+		vals[key] = val
+	}
+
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := []byte{'{'}
+	for i, k := range keys {
+		if i > 0 {
+			out = append(out, ',')
+		}
+
+		kRaw, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		vRaw, err := json.Marshal(vals[k])
+		if err != nil {
+			return nil, errors.Wrap(err, "could not encode value", "key", k)
+		}
+
+		out = append(out, kRaw...)
+		out = append(out, ':')
+		out = append(out, vRaw...)
+	}
+	out = append(out, '}')
+
+	return out, nil
+}
+
+// OpenFile opens (creating if necessary) an audit file for appending, verifying any
+// records already present and returning the head to Resume from
+func OpenFile(path string) (*os.File, Head, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600) //nolint:gosec // caller controls the path
+	if err != nil {
+		return nil, Head{}, errors.Wrap(err, "could not open audit file", "path", path)
+	}
+
+	head, err := Verify(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, Head{}, errors.Wrap(err, "existing audit file failed verification", "path", path)
+	}
+
+	return f, head, nil
+}
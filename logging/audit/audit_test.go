@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/logging"
+)
+
+func fixedClock() time.Time {
+	return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+func writeTrail(t *testing.T, n int) []string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	a := New(logging.NewJSONFileLogger(buf), WithClock(fixedClock))
+	for i := 0; i < n; i++ {
+		err := a.Record("user.login", "user", "alice", "attempt", i, "note", "<b>&</b>")
+		assert.NoError(t, err)
+	}
+
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	lines := writeTrail(t, 4)
+
+	tests := []struct {
+		name    string
+		lines   func() []string
+		wantSeq uint64
+		wantErr error
+	}{
+		{
+			name:    "intact",
+			lines:   func() []string { return lines },
+			wantSeq: 4,
+		},
+		{
+			name:    "removed record",
+			lines:   func() []string { return []string{lines[0], lines[2], lines[3]} },
+			wantSeq: 1,
+			wantErr: ErrGap,
+		},
+		{
+			name: "modified data",
+			lines: func() []string {
+				return []string{lines[0], strings.Replace(lines[1], "alice", "mallory", 1), lines[2]}
+			},
+			wantSeq: 1,
+			wantErr: ErrTampered,
+		},
+		{
+			name: "rewritten sequence",
+			lines: func() []string {
+				return []string{lines[0], strings.Replace(lines[2], `"seq":3`, `"seq":2`, 1)}
+			},
+			wantSeq: 1,
+			wantErr: ErrBrokenChain,
+		},
+		{
+			name:    "garbage",
+			lines:   func() []string { return []string{lines[0], "not json"} },
+			wantSeq: 1,
+			wantErr: ErrMalformed,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			head, err := Verify(strings.NewReader(strings.Join(tt.lines(), "\n") + "\n"))
+			assert.Equal(t, tt.wantSeq, head.Seq)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.wantErr), "error %v should be %v", err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerify_malformedCause(t *testing.T) {
+	t.Parallel()
+
+	_, err := Verify(strings.NewReader("not json\n"))
+	assert.True(t, errors.Is(err, ErrMalformed))
+
+	var malformed malformedError
+	assert.True(t, errors.As(err, &malformed))
+	assert.Error(t, malformed.cause, "the parse error is kept as the cause")
+	assert.True(t, strings.HasPrefix(err.Error(), "malformed audit record: json: "), err.Error())
+}
+
+func TestOpenFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for i := 0; i < 2; i++ {
+		f, head, err := OpenFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i*2), head.Seq)
+
+		a := New(logging.NewJSONFileLogger(f), Resume(head))
+		assert.NoError(t, a.Record("one"))
+		assert.NoError(t, a.Record("two", "k", "v"))
+		assert.NoError(t, f.Close())
+	}
+
+	f, head, err := OpenFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), head.Seq)
+	assert.NoError(t, f.Close())
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+)
+
+var (
+	// ErrMalformed indicates a line that is not a complete audit record
+	ErrMalformed = errors.New("malformed audit record")
+	// ErrGap indicates a record whose sequence number does not follow the previous one
+	ErrGap = errors.New("audit sequence gap")
+	// ErrBrokenChain indicates a record whose prev_hash is not the hash of the previous record
+	ErrBrokenChain = errors.New("audit hash chain broken")
+	// ErrTampered indicates a record whose contents do not match its hash
+	ErrTampered = errors.New("audit record modified")
+)
+
+// Verify reads an audit trail from its first record, checking the sequence numbers and
+// hash chain. It returns the head of the trail, or an error matching (with errors.Is)
+// one of ErrMalformed, ErrGap, ErrBrokenChain, or ErrTampered and carrying the line number.
+func Verify(r io.Reader) (Head, error) {
+	return VerifyFrom(r, Head{})
+}
+
+// VerifyFrom is Verify for a trail that continues after the provided head (e.g., a rotated file)
+func VerifyFrom(r io.Reader, head Head) (Head, error) {
+	br := bufio.NewReader(r)
+
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return head, errors.Wrap(err, "could not read audit trail", "line", lineNo)
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			next, verr := verifyRecord(line, head)
+			if verr != nil {
+				return head, errors.WithDetails(verr, "line", lineNo)
+			}
+			head = next
+		}
+
+		if err == io.EOF {
+			return head, nil
+		}
+	}
+}
+
+func verifyRecord(line []byte, prev Head) (Head, error) {
+	var rec map[string]json.RawMessage
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return prev, malformedError{cause: err}
+	}
+
+	raws := make([][]byte, 0, 6)
+	for _, key := range []string{KeySeq, KeyTime, KeyEvent, KeyData, KeyPrevHash, KeyHash} {
+		raw, ok := rec[key]
+		if !ok {
+			return prev, errors.Wrap(ErrMalformed, "missing key", "key", key)
+		}
+		raws = append(raws, raw)
+	}
+
+	seq, err := strconv.ParseUint(string(raws[0]), 10, 64)
+	if err != nil {
+		return prev, errors.Wrap(ErrMalformed, "invalid sequence number")
+	}
+
+	var prevHash, hash string
+	if json.Unmarshal(raws[4], &prevHash) != nil {
+		return prev, errors.Wrap(ErrMalformed, "invalid prev_hash")
+	}
+
+	if json.Unmarshal(raws[5], &hash) != nil {
+		return prev, errors.Wrap(ErrMalformed, "invalid hash")
+	}
+
+	if seq != prev.Seq+1 {
+		return prev, errors.Wrap(ErrGap, "", "seq", seq, "expected_seq", prev.Seq+1)
+	}
+
+	if prevHash != prev.Hash {
+		return prev, errors.Wrap(ErrBrokenChain, "", "seq", seq)
+	}
+
+	if chainHash(raws[0], raws[1], raws[2], raws[3], raws[4]) != hash {
+		return prev, errors.Wrap(ErrTampered, "", "seq", seq)
+	}
+
+	return Head{Seq: seq, Hash: hash}, nil
+}
+
+// malformedError is a line that could not be parsed, matching both ErrMalformed and the
+// parse error
+type malformedError struct {
+	cause error
+}
+
+func (e malformedError) Error() string {
+	return ErrMalformed.Error() + ": " + e.cause.Error()
+}
+
+func (e malformedError) Unwrap() []error {
+	return []error{ErrMalformed, e.cause}
+}
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type expensive struct {
+	vals []int
+}
+
+func newExpensive() expensive {
+	e := expensive{vals: make([]int, 256)}
+	for i := range e.vals {
+		e.vals[i] = i
+	}
+
+	return e
+}
+
+func (e expensive) dump() string {
+	return fmt.Sprintf("%#v", e.vals)
+}
+
+func BenchmarkMessageFilteredEager(b *testing.B) {
+	e := newExpensive()
+	l := AtLevel(WithLevel(NewJSONFileLogger(io.Discard), "info"), LevelDebug)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("state", "dump", e.dump())
+	}
+}
+
+func BenchmarkMessageFilteredLazy(b *testing.B) {
+	e := newExpensive()
+	l := AtLevel(WithLevel(NewJSONFileLogger(io.Discard), "info"), LevelDebug)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("state", "dump", Lazy(func() interface{} { return e.dump() }))
+	}
+}
+
+func BenchmarkMessageEmittedEager(b *testing.B) {
+	e := newExpensive()
+	l := AtLevel(WithLevel(NewJSONFileLogger(io.Discard), "info"), LevelError)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("state", "dump", e.dump())
+	}
+}
+
+func BenchmarkMessageEmittedLazy(b *testing.B) {
+	e := newExpensive()
+	l := AtLevel(WithLevel(NewJSONFileLogger(io.Discard), "info"), LevelError)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("state", "dump", Lazy(func() interface{} { return e.dump() }))
+	}
+}
+
+func BenchmarkKeyvalsJSON(b *testing.B) {
+	l := AtLevel(With(NewJSONFileLogger(io.Discard), "service", "bench"), LevelInfo)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("request", "method", "GET", "status", 200, "bytes", i, "elapsed", time.Millisecond)
+	}
+}
+
+func BenchmarkFieldsJSON(b *testing.B) {
+	l := AtLevel(With(NewJSONFileLogger(io.Discard), "service", "bench"), LevelInfo)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MessageFields(l, "request", String("method", "GET"), Int("status", 200), Int("bytes", i), Duration("elapsed", time.Millisecond))
+	}
+}
+
+func BenchmarkKeyvalsLogfmt(b *testing.B) {
+	l := AtLevel(With(NewLogfmtFileLogger(io.Discard), "service", "bench"), LevelInfo)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Message("request", "method", "GET", "status", 200, "bytes", i, "ok", true)
+	}
+}
+
+func BenchmarkFieldsLogfmt(b *testing.B) {
+	l := AtLevel(With(NewLogfmtFileLogger(io.Discard), "service", "bench"), LevelInfo)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MessageFields(l, "request", String("method", "GET"), Int("status", 200), Int("bytes", i), Bool("ok", true))
+	}
+}
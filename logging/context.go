@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-kit/log" //nolint:depguard,staticcheck // uses this internally to do the logging
+
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+const loggerContextKey = request.ContextKey("logger")
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	SetDefault(NewFrom(log.NewNopLogger()))
+}
+
+// SetDefault sets the logger that FromContext returns when a context does not carry one.
+// Initially this is a logger that discards everything.
+func SetDefault(l Logger) {
+	defaultLogger.Store(&l)
+}
+
+// IntoContext creates a new context from an existing context carrying the provided logger
+func IntoContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext retrieves the logger stored by IntoContext, or the default logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey).(Logger); ok {
+		return l
+	}
+
+	return *defaultLogger.Load()
+}
+
+// WithContextInto is WithContext, additionally storing the derived logger in the returned context
+func WithContextInto(ctx context.Context, logger Logger, keyvals ...interface{}) (context.Context, Logger) {
+	logger = WithContext(ctx, logger, keyvals...)
+	return IntoContext(ctx, logger), logger
+}
+
+// WithRequestInto is WithRequest, additionally storing the derived logger in the context of the
+// returned request
+func WithRequestInto(req *http.Request, l Logger, keyvals ...interface{}) (*http.Request, Logger) {
+	l = WithRequest(req, l, keyvals...)
+	return req.WithContext(IntoContext(req.Context(), l)), l
+}
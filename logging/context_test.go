@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gsmcwhirter/go-util/v12/request"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	dummy := &dummyLogger{}
+	stored := NewFrom(dummy)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Logger
+	}{
+		{
+			name: "stored logger",
+			ctx:  IntoContext(context.Background(), stored),
+			want: stored,
+		},
+		{
+			name: "default logger",
+			ctx:  context.Background(),
+			want: *defaultLogger.Load(),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithContextInto(t *testing.T) {
+	t.Parallel()
+
+	dummy := &dummyLogger{}
+	rid := request.GenerateRequestID()
+	ctx := request.NewRequestContextWithRequestID(context.Background(), rid)
+
+	ctx, _ = WithContextInto(ctx, NewFrom(dummy), "foo", "bar")
+	FromContext(ctx).Message("test")
+
+	want := [][]interface{}{{"foo", "bar", "request_id", rid, "message", "test"}}
+	if !reflect.DeepEqual(dummy.lines, want) {
+		t.Errorf("logger.Log() output = %v, want %v", dummy.lines, want)
+	}
+}
+
+func TestWithRequestInto(t *testing.T) {
+	t.Parallel()
+
+	dummy := &dummyLogger{}
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+
+	req, _ = WithRequestInto(req, NewFrom(dummy))
+	FromContext(req.Context()).Message("test")
+
+	want := [][]interface{}{{
+		"request_host", "example.com",
+		"request_method", "GET",
+		"request_uri", "http://example.com/foo",
+		"request_id", "unknown",
+		"message", "test",
+	}}
+	if !reflect.DeepEqual(dummy.lines, want) {
+		t.Errorf("logger.Log() output = %v, want %v", dummy.lines, want)
+	}
+}
@@ -0,0 +1,393 @@
+package logging
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kit/log" //nolint:depguard,staticcheck // uses this internally to do the logging
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+	"github.com/gsmcwhirter/go-util/v12/json"
+	"github.com/gsmcwhirter/go-util/v12/pool"
+)
+
+type format int
+
+const (
+	formatJSON format = iota
+	formatLogfmt
+)
+
+var lineBufPool = pool.NewBufferPool(1024)
+
+// encoder is the terminal BaseLogger for the loggers constructed by this package.
+// Key/value lines are handed to the go-kit encoders, while typed fields are written
+// directly into a pooled buffer.
+type encoder struct {
+	format format
+	w      io.Writer
+	kit    BaseLogger
+	policy KeyPolicy
+}
+
+func newEncoder(f format, w io.Writer, opts ...EncoderOpt) *encoder {
+	sw := log.NewSyncWriter(w)
+
+	var kit BaseLogger
+	switch f {
+	case formatLogfmt:
+		kit = log.NewLogfmtLogger(sw)
+	default:
+		kit = log.NewJSONLogger(sw)
+	}
+
+	e := &encoder{
+		format: f,
+		w:      sw,
+		kit:    kit,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *encoder) Log(keyvals ...interface{}) error {
+	return e.kit.Log(e.policy.applyKeyvals(resolveLazy(keyvals))...)
+}
+
+// writeFields writes a single line consisting of the context fields, the message,
+// the error (if any), the line fields, and finally any error data
+func (e *encoder) writeFields(ctx []Field, msg string, err error, fields []Field, data []interface{}) error {
+	if e.policy.active() {
+		return e.writePolicyFields(ctx, msg, err, fields, data)
+	}
+
+	buf := lineBufPool.Get()
+	defer lineBufPool.Put(buf)
+
+	if e.format == formatJSON {
+		buf.WriteByte('{')
+	}
+
+	first := true
+	for i := range ctx {
+		e.appendField(buf, &ctx[i], first)
+		first = false
+	}
+
+	msgField := String("message", msg)
+	e.appendField(buf, &msgField, first)
+
+	if err != nil {
+		errField := Err(err)
+		if errs, ok := err.(errors.Error); ok {
+			errField = String("error", errs.Msg())
+		}
+		e.appendField(buf, &errField, false)
+	}
+
+	for i := range fields {
+		e.appendField(buf, &fields[i], false)
+	}
+
+	for i := 0; i < len(data); i += 2 {
+		var val interface{} = log.ErrMissingValue
+		if i+1 < len(data) {
+			val = data[i+1]
+		}
+
+		dataField := Any(fmt.Sprint(data[i]), val)
+		e.appendField(buf, &dataField, false)
+	}
+
+	if e.format == formatJSON {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('\n')
+
+	_, writeErr := e.w.Write(buf.Bytes())
+	return writeErr
+}
+
+// writePolicyFields collects the whole line so that the key policy can be applied
+// across all of it before it is written
+func (e *encoder) writePolicyFields(ctx []Field, msg string, err error, fields []Field, data []interface{}) error {
+	all := make([]Field, 0, len(ctx)+len(fields)+len(data)/2+2)
+	all = append(all, ctx...)
+	all = append(all, String("message", msg))
+
+	if err != nil {
+		if errs, ok := err.(errors.Error); ok {
+			all = append(all, String("error", errs.Msg()))
+		} else {
+			all = append(all, Err(err))
+		}
+	}
+
+	all = append(all, fields...)
+	if withData, ok := keyvalsToFields(all, data); ok {
+		all = withData
+	} else {
+		// unbound valuers are written as-is, as in writeFields
+		for i := 0; i < len(data); i += 2 {
+			var val interface{} = log.ErrMissingValue
+			if i+1 < len(data) {
+				val = data[i+1]
+			}
+
+			all = append(all, Any(fmt.Sprint(data[i]), val))
+		}
+	}
+	all = e.policy.applyFields(all)
+
+	buf := lineBufPool.Get()
+	defer lineBufPool.Put(buf)
+
+	if e.format == formatJSON {
+		buf.WriteByte('{')
+	}
+
+	for i := range all {
+		e.appendField(buf, &all[i], i == 0)
+	}
+
+	if e.format == formatJSON {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('\n')
+
+	_, writeErr := e.w.Write(buf.Bytes())
+	return writeErr
+}
+
+func (e *encoder) appendField(buf *bytes.Buffer, f *Field, first bool) {
+	if e.format == formatJSON {
+		if !first {
+			buf.WriteByte(',')
+		}
+		appendJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		appendJSONValue(buf, f)
+
+		return
+	}
+
+	if !first {
+		buf.WriteByte(' ')
+	}
+	appendLogfmtKey(buf, f.Key)
+	buf.WriteByte('=')
+	appendLogfmtValue(buf, f)
+}
+
+func appendJSONValue(buf *bytes.Buffer, f *Field) {
+	switch f.kind {
+	case stringKind:
+		appendJSONString(buf, f.str)
+	case intKind:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), f.num, 10))
+	case floatKind:
+		appendJSONFloat(buf, math.Float64frombits(uint64(f.num)))
+	case boolKind:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), f.num != 0))
+	case durationKind:
+		appendJSONString(buf, time.Duration(f.num).String())
+	case errorKind:
+		if f.iface == nil {
+			buf.WriteString("null")
+			return
+		}
+		appendJSONString(buf, f.iface.(error).Error())
+	default:
+		appendJSONAny(buf, f.iface)
+	}
+}
+
+// appendJSONAny follows the go-kit JSON logger: marshalers take priority, then
+// errors and Stringers are rendered as strings
+func appendJSONAny(buf *bytes.Buffer, v interface{}) {
+	if lz, ok := v.(Lazy); ok {
+		v = lz()
+	}
+
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return
+	case string:
+		appendJSONString(buf, x)
+		return
+	case json.Marshaler, encoding.TextMarshaler:
+	case error:
+		appendJSONString(buf, x.Error())
+		return
+	case fmt.Stringer:
+		appendJSONString(buf, x.String())
+		return
+	}
+
+	d, err := json.Marshal(v)
+	if err != nil {
+		appendJSONString(buf, fmt.Sprintf("%+v", v))
+		return
+	}
+	buf.Write(d)
+}
+
+func appendJSONFloat(buf *bytes.Buffer, f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+		return
+	}
+
+	abs := math.Abs(f)
+	fmtByte := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		fmtByte = 'e'
+	}
+	buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), f, fmtByte, -1, 64))
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf.WriteString(s[start:i])
+				buf.WriteString("\ufffd")
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+
+		if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+
+		buf.WriteString(s[start:i])
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// appendLogfmtKey writes the key, replacing anything logfmt does not allow in keys
+func appendLogfmtKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			buf.WriteByte('_')
+			continue
+		}
+		buf.WriteRune(r)
+	}
+}
+
+func appendLogfmtValue(buf *bytes.Buffer, f *Field) {
+	switch f.kind {
+	case stringKind:
+		appendLogfmtString(buf, f.str)
+	case intKind:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), f.num, 10))
+	case floatKind:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), math.Float64frombits(uint64(f.num)), 'f', -1, 64))
+	case boolKind:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), f.num != 0))
+	case durationKind:
+		appendLogfmtString(buf, time.Duration(f.num).String())
+	case errorKind:
+		if f.iface == nil {
+			buf.WriteString("null")
+			return
+		}
+		appendLogfmtString(buf, f.iface.(error).Error())
+	default:
+		appendLogfmtAny(buf, f.iface)
+	}
+}
+
+// appendLogfmtAny follows the go-logfmt value rules
+func appendLogfmtAny(buf *bytes.Buffer, v interface{}) {
+	if lz, ok := v.(Lazy); ok {
+		v = lz()
+	}
+
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendLogfmtString(buf, x)
+	case []byte:
+		appendLogfmtString(buf, string(x))
+	case encoding.TextMarshaler:
+		d, err := x.MarshalText()
+		if err != nil {
+			appendLogfmtString(buf, err.Error())
+			return
+		}
+		appendLogfmtString(buf, string(d))
+	case error:
+		appendLogfmtString(buf, x.Error())
+	case fmt.Stringer:
+		appendLogfmtString(buf, x.String())
+	default:
+		appendLogfmtString(buf, fmt.Sprint(v))
+	}
+}
+
+func appendLogfmtString(buf *bytes.Buffer, s string) {
+	needsQuotes := false
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			needsQuotes = true
+			break
+		}
+	}
+
+	if !needsQuotes {
+		buf.WriteString(s)
+		return
+	}
+
+	appendJSONString(buf, s)
+}
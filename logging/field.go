@@ -0,0 +1,243 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-kit/log" //nolint:depguard,staticcheck // uses this internally to do the logging
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+type fieldKind uint8
+
+const (
+	anyKind fieldKind = iota
+	stringKind
+	intKind
+	floatKind
+	boolKind
+	durationKind
+	errorKind
+)
+
+// Field is a typed key/value pair. A Field can be passed anywhere keyvals are
+// accepted (taking the place of both the key and the value), and to MessageFields
+// and ErrFields to avoid boxing the value into an interface{}.
+type Field struct {
+	Key string
+
+	kind  fieldKind
+	num   int64
+	str   string
+	iface interface{}
+}
+
+// String constructs a string Field
+func String(key, val string) Field {
+	return Field{Key: key, kind: stringKind, str: val}
+}
+
+// Int constructs an integer Field
+func Int(key string, val int) Field {
+	return Field{Key: key, kind: intKind, num: int64(val)}
+}
+
+// Int64 constructs an integer Field
+func Int64(key string, val int64) Field {
+	return Field{Key: key, kind: intKind, num: val}
+}
+
+// Float64 constructs a floating point Field
+func Float64(key string, val float64) Field {
+	return Field{Key: key, kind: floatKind, num: int64(math.Float64bits(val))}
+}
+
+// Bool constructs a boolean Field
+func Bool(key string, val bool) Field {
+	var n int64
+	if val {
+		n = 1
+	}
+
+	return Field{Key: key, kind: boolKind, num: n}
+}
+
+// Duration constructs a Field that renders as time.Duration.String() does
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, kind: durationKind, num: int64(val)}
+}
+
+// Err constructs a Field with the key "error"
+func Err(err error) Field {
+	return Field{Key: "error", kind: errorKind, iface: err}
+}
+
+// Any constructs a Field holding an arbitrary value, encoded as it would be
+// if passed as a plain key/value pair
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, kind: anyKind, iface: val}
+}
+
+// Value returns the value of the field as an interface{}
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case stringKind:
+		return f.str
+	case intKind:
+		return f.num
+	case floatKind:
+		return math.Float64frombits(uint64(f.num))
+	case boolKind:
+		return f.num != 0
+	case durationKind:
+		return time.Duration(f.num)
+	default:
+		return f.iface
+	}
+}
+
+// expandFields replaces any Field in key position with its key and value,
+// returning keyvals unchanged if there are none
+func expandFields(keyvals []interface{}) []interface{} {
+	found := false
+	for i := 0; i < len(keyvals); i += 2 {
+		if _, ok := keyvals[i].(Field); ok {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return keyvals
+	}
+
+	expanded := make([]interface{}, 0, len(keyvals)+4)
+	for i := 0; i < len(keyvals); {
+		if f, ok := keyvals[i].(Field); ok {
+			expanded = append(expanded, f.Key, f.Value())
+			i++
+			continue
+		}
+
+		expanded = append(expanded, keyvals[i])
+		if i+1 < len(keyvals) {
+			expanded = append(expanded, keyvals[i+1])
+		}
+		i += 2
+	}
+
+	return expanded
+}
+
+// appendFieldKeyvals appends the fields to args as key/value pairs
+func appendFieldKeyvals(args []interface{}, fields []Field) []interface{} {
+	for i := range fields {
+		args = append(args, fields[i].Key, fields[i].Value())
+	}
+
+	return args
+}
+
+// keyvalsToFields converts (already expanded) keyvals into fields. It reports
+// false if any value is a log.Valuer, since those must be bound by go-kit.
+func keyvalsToFields(fields []Field, keyvals []interface{}) ([]Field, bool) {
+	for i := 0; i < len(keyvals); i += 2 {
+		var val interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+
+		if _, ok := val.(log.Valuer); ok {
+			return nil, false
+		}
+
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+
+		fields = append(fields, Any(key, val))
+	}
+
+	return fields, true
+}
+
+// errKeyvals builds the key/value pairs that Logger.Err emits for msg and err
+func errKeyvals(msg string, err error, extra int) []interface{} {
+	if e, ok := err.(errors.Error); ok {
+		return append(make([]interface{}, 0, 4+extra+len(e.Data())), "message", msg, "error", e.Msg())
+	}
+
+	return append(make([]interface{}, 0, 4+extra), "message", msg, "error", err)
+}
+
+// errData returns the data attached to err, if it is an errors.Error
+func errData(err error) []interface{} {
+	if e, ok := err.(errors.Error); ok {
+		return e.Data()
+	}
+
+	return nil
+}
+
+// MessageFields logs msg with the provided typed fields. When l writes directly to
+// one of this package's encoders, the fields are encoded without boxing their values;
+// otherwise they are passed along as key/value pairs.
+func MessageFields(l Logger, msg string, fields ...Field) {
+	lg, ok := l.(*logger)
+	if !ok {
+		l.Message(msg, appendFieldKeyvals(make([]interface{}, 0, 2*len(fields)), fields)...)
+		return
+	}
+
+	if !lg.enabled() {
+		return
+	}
+
+	fields = groupFields(lg.group, fields)
+
+	if lg.sink != nil {
+		if err := lg.sink.writeFields(lg.fields, msg, nil, fields, nil); err != nil {
+			panic(errors.WithDetails(err, "message", msg))
+		}
+
+		return
+	}
+
+	args := appendFieldKeyvals(append(make([]interface{}, 0, 2+2*len(fields)), "message", msg), fields)
+	if err := lg.base.Log(args...); err != nil {
+		panic(errors.WithDetails(err, args...))
+	}
+}
+
+// ErrFields logs msg and err with the provided typed fields, in the same way that
+// MessageFields does
+func ErrFields(l Logger, msg string, err error, fields ...Field) {
+	lg, ok := l.(*logger)
+	if !ok {
+		l.Err(msg, err, appendFieldKeyvals(make([]interface{}, 0, 2*len(fields)), fields)...)
+		return
+	}
+
+	if !lg.enabled() {
+		return
+	}
+
+	fields = groupFields(lg.group, fields)
+
+	if lg.sink != nil {
+		if logErr := lg.sink.writeFields(lg.fields, msg, err, fields, groupKeyvals(lg.group, errData(err))); logErr != nil {
+			panic(errors.WithDetails(logErr, "message", msg))
+		}
+
+		return
+	}
+
+	args := appendFieldKeyvals(errKeyvals(msg, err, 2*len(fields)), fields)
+	args = append(args, groupKeyvals(lg.group, errData(err))...)
+	if logErr := lg.base.Log(args...); logErr != nil {
+		panic(errors.WithDetails(logErr, args...))
+	}
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"errors" //nolint:depguard // used to test wrapping
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log" //nolint:depguard,staticcheck // used to test valuers
+
+	errs "github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func Test_expandFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keyvals []interface{}
+		want    []interface{}
+	}{
+		{
+			name:    "no fields",
+			keyvals: []interface{}{"a", 1},
+			want:    []interface{}{"a", 1},
+		},
+		{
+			name:    "mixed",
+			keyvals: []interface{}{"a", 1, String("b", "x"), "c", 2, Int("d", 3)},
+			want:    []interface{}{"a", 1, "b", "x", "c", 2, "d", int64(3)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := expandFields(tt.keyvals); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageFields(t *testing.T) {
+	t.Parallel()
+
+	testErr := errs.WithDetails(errors.New("boom"), "detail", 1)
+
+	tests := []struct {
+		name    string
+		logfmt  bool
+		log     func(l Logger)
+		wantOut string
+	}{
+		{
+			name: "json typed path",
+			log: func(l Logger) {
+				MessageFields(AtLevel(With(l, "svc", "x"), LevelInfo), "hi", String("s", "a\"b"), Int("n", 3), Bool("ok", true), Duration("d", time.Second))
+			},
+			wantOut: `{"level":"info","svc":"x","message":"hi","s":"a\"b","n":3,"ok":true,"d":"1s"}` + "\n",
+		},
+		{
+			name:   "logfmt typed path",
+			logfmt: true,
+			log: func(l Logger) {
+				MessageFields(With(l, "svc", "x"), "hi there", String("s", "a"), Float64("f", 1.5))
+			},
+			wantOut: `svc=x message="hi there" s=a f=1.5` + "\n",
+		},
+		{
+			name:   "logfmt typed error",
+			logfmt: true,
+			log: func(l Logger) {
+				ErrFields(l, "failed", testErr, Int("n", 1))
+			},
+			wantOut: `message=failed error=boom n=1 detail=1` + "\n",
+		},
+		{
+			name:   "valuer falls back",
+			logfmt: true,
+			log: func(l Logger) {
+				MessageFields(With(l, "v", valuer), "hi", Int("n", 1))
+			},
+			wantOut: `v=val message=hi n=1` + "\n",
+		},
+		{
+			name:   "fields in keyvals",
+			logfmt: true,
+			log: func(l Logger) {
+				l.Message("hi", Int("n", 1), "k", "v")
+			},
+			wantOut: `message=hi n=1 k=v` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			l := NewJSONFileLogger(buf)
+			if tt.logfmt {
+				l = NewLogfmtFileLogger(buf)
+			}
+
+			tt.log(l)
+
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+var valuer log.Valuer = func() interface{} {
+	return "val"
+}
@@ -0,0 +1,245 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// KeyStyle determines how keys are rewritten before a line is encoded
+type KeyStyle int
+
+const (
+	// KeyStyleAsIs leaves keys untouched
+	KeyStyleAsIs KeyStyle = iota
+	// KeyStyleSnake rewrites keys like "http.method" and "requestID" to "http_method" and "request_id"
+	KeyStyleSnake
+	// KeyStyleCamel rewrites keys like "http.method" and "request_id" to "httpMethod" and "requestId"
+	KeyStyleCamel
+)
+
+// KeyPolicy configures how an encoder treats the keys of each line
+//
+// - Dedupe keeps only the last value for a repeated key, at the position the key first appeared
+// - Style normalizes every string key (including those produced by WithGroup)
+type KeyPolicy struct {
+	Dedupe bool
+	Style  KeyStyle
+}
+
+// EncoderOpt configures the loggers created by NewJSONLogger, NewLogfmtLogger,
+// NewJSONFileLogger, and NewLogfmtFileLogger
+type EncoderOpt func(*encoder)
+
+// UseKeyPolicy applies the provided KeyPolicy to every line written, whether it was
+// logged with key/value pairs or typed fields
+func UseKeyPolicy(p KeyPolicy) EncoderOpt {
+	return func(e *encoder) {
+		e.policy = p
+	}
+}
+
+// groupSeparator joins group names and keys; KeyStyleSnake and KeyStyleCamel rewrite it
+const groupSeparator = "."
+
+// WithGroup wraps a logger so that keys subsequently passed to With, Message, Err,
+// MessageFields, and ErrFields (including the data of the error) are namespaced under name
+// (e.g., "db.query"). Groups nest. The message and error keys are not namespaced, and
+// neither are the keyvals passed to Log, which are logged as-is.
+func WithGroup(l Logger, name string) Logger {
+	child := derive(l, BaseFrom(l))
+	child.group += name + groupSeparator
+
+	return child
+}
+
+func (p KeyPolicy) active() bool {
+	return p.Dedupe || p.Style != KeyStyleAsIs
+}
+
+func (p KeyPolicy) normalize(key string) string {
+	switch p.Style {
+	case KeyStyleSnake:
+		return snakeKey(key)
+	case KeyStyleCamel:
+		return camelKey(key)
+	default:
+		return key
+	}
+}
+
+// applyKeyvals returns keyvals with the policy applied, copying only if needed
+func (p KeyPolicy) applyKeyvals(keyvals []interface{}) []interface{} {
+	if !p.active() {
+		return keyvals
+	}
+
+	out := make([]interface{}, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		key := keyvals[i]
+		if s, ok := key.(string); ok {
+			key = p.normalize(s)
+		}
+
+		var val interface{}
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+
+		if s, ok := key.(string); ok && p.Dedupe {
+			if j := indexKeyval(out, s); j >= 0 {
+				out[j+1] = val
+				continue
+			}
+		}
+
+		out = append(out, key)
+		if i+1 < len(keyvals) {
+			out = append(out, val)
+		}
+	}
+
+	return out
+}
+
+func indexKeyval(keyvals []interface{}, key string) int {
+	for j := 0; j < len(keyvals)-1; j += 2 {
+		if s, ok := keyvals[j].(string); ok && s == key {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// applyFields returns fields with the policy applied, copying if needed
+func (p KeyPolicy) applyFields(fields []Field) []Field {
+	if !p.active() {
+		return fields
+	}
+
+	out := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		f.Key = p.normalize(f.Key)
+
+		if p.Dedupe {
+			if j := indexField(out, f.Key); j >= 0 {
+				out[j] = f
+				continue
+			}
+		}
+
+		out = append(out, f)
+	}
+
+	return out
+}
+
+func indexField(fields []Field, key string) int {
+	for j := range fields {
+		if fields[j].Key == key {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// groupKeyvals prefixes the keys in (already expanded) keyvals with group
+func groupKeyvals(group string, keyvals []interface{}) []interface{} {
+	if group == "" {
+		return keyvals
+	}
+
+	grouped := make([]interface{}, len(keyvals))
+	copy(grouped, keyvals)
+	for i := 0; i < len(grouped); i += 2 {
+		if s, ok := grouped[i].(string); ok {
+			grouped[i] = group + s
+		} else {
+			grouped[i] = group + fmt.Sprint(grouped[i])
+		}
+	}
+
+	return grouped
+}
+
+// groupFields prefixes the keys of fields with group
+func groupFields(group string, fields []Field) []Field {
+	if group == "" {
+		return fields
+	}
+
+	grouped := make([]Field, len(fields))
+	copy(grouped, fields)
+	for i := range grouped {
+		grouped[i].Key = group + grouped[i].Key
+	}
+
+	return grouped
+}
+
+func isKeySeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-' || r == ' ' || r == '/'
+}
+
+func snakeKey(key string) string {
+	if strings.IndexFunc(key, func(r rune) bool { return unicode.IsUpper(r) || (r != '_' && isKeySeparator(r)) }) < 0 {
+		return key
+	}
+
+	runes := []rune(key)
+
+	var sb strings.Builder
+	sb.Grow(len(key) + 4)
+
+	lastSep := true // suppresses leading separators
+	for i, r := range runes {
+		if isKeySeparator(r) {
+			if !lastSep {
+				sb.WriteByte('_')
+				lastSep = true
+			}
+			continue
+		}
+
+		// start a new word at "aB", "1B", and at the last capital of "ABc"
+		if unicode.IsUpper(r) && !lastSep {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+		lastSep = false
+	}
+
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+func camelKey(key string) string {
+	if strings.IndexFunc(key, isKeySeparator) < 0 {
+		return key
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(key))
+
+	upperNext := false
+	for _, r := range key {
+		if isKeySeparator(r) {
+			upperNext = sb.Len() > 0
+			continue
+		}
+
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log" //nolint:depguard,staticcheck // for log.Valuer
+
+	"github.com/gsmcwhirter/go-util/v12/errors"
+)
+
+func Test_snakeKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "request_id", want: "request_id"},
+		{key: "http.method", want: "http_method"},
+		{key: "requestID", want: "request_id"},
+		{key: "HTTPMethod", want: "http_method"},
+		{key: "db.rowsAffected", want: "db_rows_affected"},
+		{key: "_leading..double", want: "leading_double"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			if got := snakeKey(tt.key); got != tt.want {
+				t.Errorf("snakeKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_camelKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "requestId", want: "requestId"},
+		{key: "request_id", want: "requestId"},
+		{key: "http.method", want: "httpMethod"},
+		{key: "_leading", want: "leading"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			if got := camelKey(tt.key); got != tt.want {
+				t.Errorf("camelKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseKeyPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := UseKeyPolicy(KeyPolicy{Dedupe: true, Style: KeyStyleSnake})
+
+	tests := []struct {
+		name    string
+		logfmt  bool
+		log     func(l Logger)
+		wantOut string
+	}{
+		{
+			name:   "logfmt keyvals",
+			logfmt: true,
+			log: func(l Logger) {
+				l = With(With(l, "request_id", "a", "http.method", "GET"), "request_id", "b")
+				l.Message("hi", "requestID", "c")
+			},
+			wantOut: `request_id=c http_method=GET message=hi` + "\n",
+		},
+		{
+			name:   "logfmt fields",
+			logfmt: true,
+			log: func(l Logger) {
+				l = With(With(l, "request_id", "a", "http.method", "GET"), "request_id", "b")
+				MessageFields(l, "hi", String("requestID", "c"))
+			},
+			wantOut: `request_id=c http_method=GET message=hi` + "\n",
+		},
+		{
+			name: "json keyvals",
+			log: func(l Logger) {
+				l = With(l, "request_id", "a", "request_id", "b")
+				l.Message("hi", "http.method", "GET")
+			},
+			wantOut: `{"http_method":"GET","message":"hi","request_id":"b"}` + "\n",
+		},
+		{
+			name: "json fields",
+			log: func(l Logger) {
+				l = With(l, "request_id", "a", "request_id", "b")
+				MessageFields(l, "hi", String("http.method", "GET"))
+			},
+			wantOut: `{"request_id":"b","message":"hi","http_method":"GET"}` + "\n",
+		},
+		{
+			name:   "logfmt groups",
+			logfmt: true,
+			log: func(l Logger) {
+				l = WithGroup(With(l, "svc", "x"), "db")
+				l = With(l, "table", "users")
+				l.Message("hi", "rowsAffected", 1)
+				MessageFields(WithGroup(l, "tx"), "hi", Int("id", 2))
+			},
+			wantOut: `svc=x db_table=users message=hi db_rows_affected=1` + "\n" +
+				`svc=x db_table=users message=hi db_tx_id=2` + "\n",
+		},
+		{
+			name:   "logfmt groups with error data and Log",
+			logfmt: true,
+			log: func(l Logger) {
+				l = WithGroup(l, "db")
+				err := errors.WithDetails(errors.New("boom"), "table", "users")
+				l.Err("failed", err, "rowsAffected", 0)
+				ErrFields(l, "failed", err, Int("rowsAffected", 0))
+				_ = l.Log("raw", true)
+			},
+			wantOut: `message=failed error=boom db_rows_affected=0 db_table=users` + "\n" +
+				`message=failed error=boom db_rows_affected=0 db_table=users` + "\n" +
+				`raw=true` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			l := NewJSONFileLogger(buf, policy)
+			if tt.logfmt {
+				l = NewLogfmtFileLogger(buf, policy)
+			}
+
+			tt.log(l)
+
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestUseKeyPolicy_errDataValuer(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	l := NewLogfmtFileLogger(buf, UseKeyPolicy(KeyPolicy{Style: KeyStyleSnake}))
+
+	valuer := log.Valuer(func() interface{} { return "x" })
+	ErrFields(l, "failed", errors.WithDetails(errors.New("boom"), "userID", 1, "at", valuer))
+
+	got := buf.String()
+	if !strings.HasPrefix(got, `message=failed error=boom user_id=1 at=`) {
+		t.Errorf("output = %q, want the error data", got)
+	}
+}
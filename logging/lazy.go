@@ -0,0 +1,68 @@
+package logging
+
+import "fmt"
+
+// Level is the severity of a log line, as set by AtLevel and filtered by WithLevel
+type Level int
+
+const (
+	LevelUnset Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (lvl Level) String() string {
+	switch lvl {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+// Lazy is a log value that is only computed when the line containing it is
+// actually written, e.g.
+//
+//	level.Debug(logger).Message("state", "dump", logging.Lazy(func() interface{} { return s.Dump() }))
+//
+// Lines dropped by WithLevel never call the function.
+type Lazy func() interface{}
+
+// String renders the value for encoders that do not resolve Lazy values themselves
+func (f Lazy) String() string {
+	return fmt.Sprint(f())
+}
+
+// resolveLazy returns keyvals with every Lazy value evaluated, copying the slice
+// only if there is something to replace
+func resolveLazy(keyvals []interface{}) []interface{} {
+	var resolved []interface{}
+
+	for i := 1; i < len(keyvals); i += 2 {
+		f, ok := keyvals[i].(Lazy)
+		if !ok {
+			continue
+		}
+
+		if resolved == nil {
+			resolved = make([]interface{}, len(keyvals))
+			copy(resolved, keyvals)
+		}
+
+		resolved[i] = f()
+	}
+
+	if resolved == nil {
+		return keyvals
+	}
+
+	return resolved
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		minLevel  string
+		lineLevel Level
+		wantCalls int
+		wantOut   bool
+	}{
+		{
+			name:      "emitted line evaluates",
+			minLevel:  "info",
+			lineLevel: LevelError,
+			wantCalls: 1,
+			wantOut:   true,
+		},
+		{
+			name:      "filtered line skips",
+			minLevel:  "info",
+			lineLevel: LevelDebug,
+			wantCalls: 0,
+			wantOut:   false,
+		},
+		{
+			name:      "unleveled line evaluates",
+			minLevel:  "error",
+			lineLevel: LevelUnset,
+			wantCalls: 1,
+			wantOut:   true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			calls := 0
+			val := Lazy(func() interface{} {
+				calls++
+				return "computed"
+			})
+
+			l := AtLevel(WithLevel(NewLogfmtFileLogger(buf), tt.minLevel), tt.lineLevel)
+			l.Message("test", "lazy", val)
+
+			if calls != tt.wantCalls {
+				t.Errorf("Lazy calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			if got := strings.Contains(buf.String(), "lazy=computed"); got != tt.wantOut {
+				t.Errorf("output = %q, want contains lazy=computed %v", buf.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func Test_resolveLazy(t *testing.T) {
+	t.Parallel()
+
+	orig := []interface{}{"a", Lazy(func() interface{} { return 1 }), "b", 2}
+	got := resolveLazy(orig)
+
+	if want := []interface{}{"a", 1, "b", 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveLazy() = %v, want %v", got, want)
+	}
+
+	if _, ok := orig[1].(Lazy); !ok {
+		t.Errorf("resolveLazy() modified its input")
+	}
+}
+
+func TestWithLevel_afterAtLevel(t *testing.T) {
+	t.Parallel()
+
+	// the filter does not see a level applied underneath it, so the line must still be written
+	dummy := &dummyLogger{}
+	l := WithLevel(AtLevel(NewFrom(dummy), LevelDebug), "info")
+	l.Message("test")
+
+	if len(dummy.lines) != 1 {
+		t.Errorf("lines = %v, want 1 line", dummy.lines)
+	}
+}
@@ -1,19 +1,21 @@
 package level
 
 import (
-	"github.com/go-kit/log/level" //nolint:depguard,staticcheck // used to implement levels
-
 	"github.com/gsmcwhirter/go-util/v12/logging"
 )
 
 func Debug(logger logging.Logger) logging.Logger {
-	return logging.NewFrom(level.Debug(logging.BaseFrom(logger)))
+	return logging.AtLevel(logger, logging.LevelDebug)
 }
 
 func Info(logger logging.Logger) logging.Logger {
-	return logging.NewFrom(level.Info(logging.BaseFrom(logger)))
+	return logging.AtLevel(logger, logging.LevelInfo)
+}
+
+func Warn(logger logging.Logger) logging.Logger {
+	return logging.AtLevel(logger, logging.LevelWarn)
 }
 
 func Error(logger logging.Logger) logging.Logger {
-	return logging.NewFrom(level.Error(logging.BaseFrom(logger)))
+	return logging.AtLevel(logger, logging.LevelError)
 }
@@ -39,13 +39,40 @@ type Logger interface {
 
 type logger struct {
 	base BaseLogger
+
+	// minLevel and lineLevel mirror the go-kit filter and level context wrapped
+	// into base, so that work for filtered lines can be skipped up front
+	minLevel  Level
+	lineLevel Level
+
+	// sink is set while base writes straight through to one of this package's
+	// encoders, with fields holding the typed equivalent of everything base adds
+	sink   *encoder
+	fields []Field
+
+	// group is the WithGroup prefix for child keys
+	group string
+}
+
+// enabled reports whether a line from this logger could pass the level filters
+// wrapped into base. It only returns false when that is certain.
+func (l *logger) enabled() bool {
+	return l.lineLevel == LevelUnset || l.lineLevel >= l.minLevel
 }
 
 func (l *logger) Log(args ...interface{}) error {
-	return l.base.Log(args...)
+	if !l.enabled() {
+		return nil
+	}
+
+	return l.base.Log(expandFields(args)...)
 }
 
 func (l *logger) Printf(f string, args ...interface{}) {
+	if !l.enabled() {
+		return
+	}
+
 	m := fmt.Sprintf(f, args...)
 	if err := l.base.Log("message", m); err != nil {
 		panic(errors.WithDetails(err, "message", m))
@@ -53,16 +80,26 @@ func (l *logger) Printf(f string, args ...interface{}) {
 }
 
 func (l *logger) Message(msg string, args ...interface{}) {
-	args = append([]interface{}{"message", msg}, args...)
+	if !l.enabled() {
+		return
+	}
+
+	args = append([]interface{}{"message", msg}, groupKeyvals(l.group, expandFields(args))...)
 	if err := l.base.Log(args...); err != nil {
 		panic(errors.WithDetails(err, args...))
 	}
 }
 
 func (l *logger) Err(msg string, err error, args ...interface{}) {
+	if !l.enabled() {
+		return
+	}
+
+	args = groupKeyvals(l.group, expandFields(args))
+
 	if e, ok := err.(errors.Error); ok {
 		args = append([]interface{}{"message", msg, "error", e.Msg()}, args...)
-		args = append(args, e.Data()...)
+		args = append(args, groupKeyvals(l.group, e.Data())...)
 		if logErr := l.base.Log(args...); logErr != nil {
 			panic(errors.WithDetails(logErr, args...))
 		}
@@ -79,12 +116,28 @@ func (l *logger) Err(msg string, err error, args ...interface{}) {
 // NewFrom wraps a BaseLogger (e.g., go-kit) in our custom extension
 func NewFrom(l BaseLogger) Logger {
 	if l2, ok := l.(*logger); ok {
-		return &logger{base: l2.base}
+		l3 := *l2
+		return &l3
+	}
+
+	if enc, ok := l.(*encoder); ok {
+		return &logger{base: enc, sink: enc}
 	}
 
 	return &logger{base: l}
 }
 
+// derive wraps base in a Logger that keeps the level information of parent
+func derive(parent Logger, base BaseLogger) *logger {
+	if p, ok := parent.(*logger); ok {
+		child := *p
+		child.base = base
+		return &child
+	}
+
+	return &logger{base: base}
+}
+
 // NewFromKitLogger wraps a BaseLogger (e.g., go-kit) in our custom extension
 var NewFromKitLogger = NewFrom
 
@@ -98,44 +151,101 @@ func BaseFrom(l Logger) BaseLogger {
 }
 
 // NewJSONLogger creates a new logger that writes json to stdout
-func NewJSONLogger() Logger {
-	return NewFrom(log.NewJSONLogger(log.NewSyncWriter(os.Stdout)))
+func NewJSONLogger(opts ...EncoderOpt) Logger {
+	return NewFrom(newEncoder(formatJSON, os.Stdout, opts...))
 }
 
 // NewLogfmtLogger creates a new logger that writes logfmt to stdout
-func NewLogfmtLogger() Logger {
-	return NewFrom(log.NewLogfmtLogger(log.NewSyncWriter(os.Stdout)))
+func NewLogfmtLogger(opts ...EncoderOpt) Logger {
+	return NewFrom(newEncoder(formatLogfmt, os.Stdout, opts...))
 }
 
-func NewJSONFileLogger(w io.Writer) Logger {
-	return NewFrom(log.NewJSONLogger(log.NewSyncWriter(w)))
+func NewJSONFileLogger(w io.Writer, opts ...EncoderOpt) Logger {
+	return NewFrom(newEncoder(formatJSON, w, opts...))
 }
 
-func NewLogfmtFileLogger(w io.Writer) Logger {
-	return NewFrom(log.NewLogfmtLogger(log.NewSyncWriter(w)))
+func NewLogfmtFileLogger(w io.Writer, opts ...EncoderOpt) Logger {
+	return NewFrom(newEncoder(formatLogfmt, w, opts...))
 }
 
 // WithLevel wraps a logger to filter out logs lower than the designated level
 func WithLevel(l Logger, levelStr string) Logger {
 	base := BaseFrom(l)
 
+	var (
+		filtered BaseLogger
+		minLevel Level
+	)
+
 	switch levelStr {
 	case "debug":
-		return NewFrom(level.NewFilter(base, level.AllowDebug()))
+		filtered, minLevel = level.NewFilter(base, level.AllowDebug()), LevelDebug
 	case "info":
-		return NewFrom(level.NewFilter(base, level.AllowInfo()))
+		filtered, minLevel = level.NewFilter(base, level.AllowInfo()), LevelInfo
 	case "warn":
-		return NewFrom(level.NewFilter(base, level.AllowWarn()))
+		filtered, minLevel = level.NewFilter(base, level.AllowWarn()), LevelWarn
 	case "error":
-		return NewFrom(level.NewFilter(base, level.AllowError()))
+		filtered, minLevel = level.NewFilter(base, level.AllowError()), LevelError
 	default:
-		return NewFrom(level.NewFilter(base, level.AllowAll()))
+		filtered, minLevel = level.NewFilter(base, level.AllowAll()), LevelUnset
 	}
+
+	child := derive(l, filtered)
+	child.minLevel = max(child.minLevel, minLevel)
+	// the new filter wraps any level context already applied, so it will not see
+	// that level on the way through; only AtLevel calls after this one count
+	child.lineLevel = LevelUnset
+
+	return child
+}
+
+// AtLevel wraps a logger so that every emitted line is marked with the provided level.
+// Lines at a level that a WithLevel filter would discard are skipped before any of
+// their arguments are processed.
+func AtLevel(l Logger, lvl Level) Logger {
+	base := BaseFrom(l)
+
+	var leveled BaseLogger
+	switch lvl {
+	case LevelDebug:
+		leveled = level.Debug(base)
+	case LevelInfo:
+		leveled = level.Info(base)
+	case LevelWarn:
+		leveled = level.Warn(base)
+	case LevelError:
+		leveled = level.Error(base)
+	default:
+		return NewFrom(l)
+	}
+
+	child := derive(l, leveled)
+	child.lineLevel = lvl
+	if child.sink != nil {
+		child.fields = append([]Field{String("level", lvl.String())}, child.fields...)
+	}
+
+	return child
 }
 
 // With wraps a logger so that every emitted line contains the provided key/val pairs
 func With(l Logger, keyvals ...interface{}) Logger {
-	return NewFrom(log.With(BaseFrom(l), keyvals...))
+	keyvals = expandFields(keyvals)
+	if p, ok := l.(*logger); ok {
+		keyvals = groupKeyvals(p.group, keyvals)
+	}
+
+	child := derive(l, log.With(BaseFrom(l), keyvals...))
+	if child.sink != nil {
+		fields, ok := keyvalsToFields(append([]Field(nil), child.fields...), keyvals)
+		if ok {
+			child.fields = fields
+		} else {
+			child.sink, child.fields = nil, nil
+		}
+	}
+
+	return child
 }
 
 // WithContext wraps a logger to include the request_id from a context in log messages
@@ -152,7 +262,7 @@ func WithContext(ctx context.Context, logger Logger, keyvals ...interface{}) Log
 func WithAttributes(logger Logger, attrs ...telemetry.KeyValue) Logger {
 	args := make([]interface{}, 0, len(attrs)*2)
 	for _, attr := range attrs {
-		args = append(args, attr.Key, attr.Value.AsInterface())
+		args = append(args, string(attr.Key), attr.Value.AsInterface())
 	}
 
 	logger = With(logger, args...)
@@ -189,3 +299,9 @@ func PatchStdLib(l Logger) {
 	stdLog.SetOutput(w)
 	stdLog.SetFlags(0)
 }
+
+// NewStdLogger creates a stdlib logger that writes through the provided one (e.g., for
+// http.Server.ErrorLog)
+func NewStdLogger(l Logger) *stdLog.Logger {
+	return stdLog.New(writer{l}, "", 0)
+}
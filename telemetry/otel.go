@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/embedded"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
@@ -55,6 +56,8 @@ var (
 	WithAttributes       = trace.WithAttributes
 	SpanFromContext      = trace.SpanFromContext
 	WithMetricAttributes = metric.WithAttributes
+	WithDescription      = metric.WithDescription
+	WithUnit             = metric.WithUnit
 )
 
 type (
@@ -145,7 +148,12 @@ func (t *Telemeter) Tracer(instrumentationName string, opts ...TracerOption) Tra
 	return t.tracerProvider.Tracer(instrumentationName, opts...)
 }
 
+// Meter returns a Meter from the configured MeterProvider, or a no-op Meter if there is none
 func (t *Telemeter) Meter(instrumentationName string, opts ...MeterOption) Meter {
+	if t.meterProvider == nil {
+		return noop.NewMeterProvider().Meter(instrumentationName, opts...)
+	}
+
 	return t.meterProvider.Meter(instrumentationName, opts...)
 }
 